@@ -0,0 +1,145 @@
+// Copyright 2024-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command pubsub-check-compatibility checks that PubSub protobuf schemas are compatible with
+// their previous revisions.
+//
+// Usage:
+//
+//	pubsub-check-compatibility [previous] [current]
+//
+// Both arguments are either a PubSub schema file, or a directory of schema files generated by
+// protoc-gen-pubsub, in which case each schema is checked against the schema with the same path.
+// Any breaking changes are printed, and the command exits with a non-zero status.
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bufbuild/protocompile"
+	"github.com/bufbuild/protoschema-plugins/internal/protoschema/pubsub"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+func main() {
+	if err := run(); err != nil {
+		if errString := err.Error(); errString != "" {
+			_, _ = fmt.Fprintln(os.Stderr, errString)
+		}
+		os.Exit(1)
+	}
+}
+
+func run() error {
+	if len(os.Args) != 3 {
+		return fmt.Errorf("usage: %s [previous] [current]", os.Args[0])
+	}
+	previousPath, currentPath := os.Args[1], os.Args[2]
+	fileInfo, err := os.Stat(previousPath)
+	if err != nil {
+		return err
+	}
+	filePaths := []string{""}
+	if fileInfo.IsDir() {
+		filePaths, err = findSchemas(previousPath)
+		if err != nil {
+			return err
+		}
+	}
+	var count int
+	for _, filePath := range filePaths {
+		incompatibilities, err := check(filepath.Join(previousPath, filePath), filepath.Join(currentPath, filePath))
+		if err != nil {
+			return err
+		}
+		for _, incompatibility := range incompatibilities {
+			if filePath != "" {
+				fmt.Printf("%s: %s\n", filePath, incompatibility)
+			} else {
+				fmt.Println(incompatibility)
+			}
+		}
+		count += len(incompatibilities)
+	}
+	if count > 0 {
+		return fmt.Errorf("found %d incompatible changes", count)
+	}
+	return nil
+}
+
+// findSchemas returns the paths of the PubSub schemas in the given directory, relative to it.
+func findSchemas(dirPath string) ([]string, error) {
+	var filePaths []string
+	err := filepath.WalkDir(dirPath, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || !strings.HasSuffix(filePath, "."+pubsub.FileExtension) {
+			return nil
+		}
+		relPath, err := filepath.Rel(dirPath, filePath)
+		if err != nil {
+			return err
+		}
+		filePaths = append(filePaths, relPath)
+		return nil
+	})
+	return filePaths, err
+}
+
+// check returns the breaking changes from the previous to the current schema file.
+func check(previousPath, currentPath string) ([]pubsub.Incompatibility, error) {
+	previous, err := loadSchema(previousPath)
+	if err != nil {
+		return nil, err
+	}
+	current, err := loadSchema(currentPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return []pubsub.Incompatibility{{
+			Path:    previous.FullName(),
+			Message: "schema removed",
+		}}, nil
+	} else if err != nil {
+		return nil, err
+	}
+	return pubsub.CheckCompatibility(previous, current), nil
+}
+
+// loadSchema compiles the given schema file, returning its root message.
+//
+// Imports are resolved relative to the directory of the file, or from the standard imports.
+func loadSchema(filePath string) (protoreflect.MessageDescriptor, error) {
+	if _, err := os.Stat(filePath); err != nil {
+		return nil, err
+	}
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{
+			ImportPaths: []string{filepath.Dir(filePath)},
+		}),
+	}
+	files, err := compiler.Compile(context.Background(), filepath.Base(filePath))
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile %s: %w", filePath, err)
+	}
+	if files[0].Messages().Len() != 1 {
+		return nil, fmt.Errorf("expected %s to contain a single message", filePath)
+	}
+	return files[0].Messages().Get(0), nil
+}
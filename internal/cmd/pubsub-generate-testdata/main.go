@@ -20,6 +20,7 @@ import (
 	"path/filepath"
 
 	"github.com/bufbuild/protoschema-plugins/internal/protoschema/golden"
+	"github.com/bufbuild/protoschema-plugins/internal/protoschema/normalize"
 	"github.com/bufbuild/protoschema-plugins/internal/protoschema/pubsub"
 )
 
@@ -64,6 +65,28 @@ func run() error {
 		if err := golden.GenerateGolden(filePath, data); err != nil {
 			return err
 		}
+		avroFilePath := filepath.Join(dirPath, fmt.Sprintf("%s.%s", testDesc.FullName(), pubsub.AvroFileExtension))
+		avroData, err := pubsub.GenerateAvro(testDesc)
+		if err != nil {
+			return err
+		}
+		if err := golden.GenerateGolden(avroFilePath, avroData); err != nil {
+			return err
+		}
+		// Generate the protobuf schema for the other syntaxes in subdirectories.
+		for _, syntax := range []normalize.Syntax{normalize.SyntaxProto3, normalize.SyntaxEditions} {
+			if err := os.MkdirAll(filepath.Join(dirPath, string(syntax)), 0755); err != nil {
+				return err
+			}
+			syntaxFilePath := filepath.Join(dirPath, string(syntax), fmt.Sprintf("%s.%s", testDesc.FullName(), pubsub.FileExtension))
+			syntaxData, err := pubsub.Generate(testDesc, pubsub.WithSyntax(syntax))
+			if err != nil {
+				return err
+			}
+			if err := golden.GenerateGolden(syntaxFilePath, syntaxData); err != nil {
+				return err
+			}
+		}
 	}
 
 	return nil
@@ -24,6 +24,9 @@ import (
 	_ "buf.build/gen/go/bufbuild/protovalidate/protocolbuffers/go/buf/validate"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	anypb "google.golang.org/protobuf/types/known/anypb"
+	durationpb "google.golang.org/protobuf/types/known/durationpb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -94,6 +97,18 @@ type ConstraintTest struct {
 	//
 	//	*ConstraintTest_RequiredImplicit_
 	//	*ConstraintTest_RequiredOptional_
+	//	*ConstraintTest_MessageOneof_
+	//	*ConstraintTest_CelRules_
+	//	*ConstraintTest_CelInt
+	//	*ConstraintTest_CelString
+	//	*ConstraintTest_CelIn
+	//	*ConstraintTest_CelUnknown
+	//	*ConstraintTest_DurationRange
+	//	*ConstraintTest_DurationIn
+	//	*ConstraintTest_TimestampRange
+	//	*ConstraintTest_TimestampLtNow
+	//	*ConstraintTest_AnyIn
+	//	*ConstraintTest_AnyNotIn
 	//	*ConstraintTest_ConstBool
 	//	*ConstraintTest_ConstEnum
 	//	*ConstraintTest_DefinedOnlyEnum
@@ -217,6 +232,9 @@ type ConstraintTest struct {
 	FiniteFloat   float32                   `protobuf:"fixed32,112,opt,name=finite_float,json=finiteFloat,proto3" json:"finite_float,omitempty"`
 	LtGtFloat     float32                   `protobuf:"fixed32,113,opt,name=lt_gt_float,json=ltGtFloat,proto3" json:"lt_gt_float,omitempty"`
 	InMap         map[string]string         `protobuf:"bytes,118,rep,name=in_map,json=inMap,proto3" json:"in_map,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	CelList       []string                  `protobuf:"bytes,132,rep,name=cel_list,json=celList,proto3" json:"cel_list,omitempty"`
+	UniqueList    []string                  `protobuf:"bytes,133,rep,name=unique_list,json=uniqueList,proto3" json:"unique_list,omitempty"`
+	PairsMap      map[string]int32          `protobuf:"bytes,134,rep,name=pairs_map,json=pairsMap,proto3" json:"pairs_map,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
 	IsList        []string                  `protobuf:"bytes,125,rep,name=is_list,json=isList,proto3" json:"is_list,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
@@ -277,6 +295,114 @@ func (x *ConstraintTest) GetRequiredOptional() *ConstraintTest_RequiredOptional
 	return nil
 }
 
+func (x *ConstraintTest) GetMessageOneof() *ConstraintTest_MessageOneof {
+	if x != nil {
+		if x, ok := x.TestCase.(*ConstraintTest_MessageOneof_); ok {
+			return x.MessageOneof
+		}
+	}
+	return nil
+}
+
+func (x *ConstraintTest) GetCelRules() *ConstraintTest_CelRules {
+	if x != nil {
+		if x, ok := x.TestCase.(*ConstraintTest_CelRules_); ok {
+			return x.CelRules
+		}
+	}
+	return nil
+}
+
+func (x *ConstraintTest) GetCelInt() int32 {
+	if x != nil {
+		if x, ok := x.TestCase.(*ConstraintTest_CelInt); ok {
+			return x.CelInt
+		}
+	}
+	return 0
+}
+
+func (x *ConstraintTest) GetCelString() string {
+	if x != nil {
+		if x, ok := x.TestCase.(*ConstraintTest_CelString); ok {
+			return x.CelString
+		}
+	}
+	return ""
+}
+
+func (x *ConstraintTest) GetCelIn() string {
+	if x != nil {
+		if x, ok := x.TestCase.(*ConstraintTest_CelIn); ok {
+			return x.CelIn
+		}
+	}
+	return ""
+}
+
+func (x *ConstraintTest) GetCelUnknown() string {
+	if x != nil {
+		if x, ok := x.TestCase.(*ConstraintTest_CelUnknown); ok {
+			return x.CelUnknown
+		}
+	}
+	return ""
+}
+
+func (x *ConstraintTest) GetDurationRange() *durationpb.Duration {
+	if x != nil {
+		if x, ok := x.TestCase.(*ConstraintTest_DurationRange); ok {
+			return x.DurationRange
+		}
+	}
+	return nil
+}
+
+func (x *ConstraintTest) GetDurationIn() *durationpb.Duration {
+	if x != nil {
+		if x, ok := x.TestCase.(*ConstraintTest_DurationIn); ok {
+			return x.DurationIn
+		}
+	}
+	return nil
+}
+
+func (x *ConstraintTest) GetTimestampRange() *timestamppb.Timestamp {
+	if x != nil {
+		if x, ok := x.TestCase.(*ConstraintTest_TimestampRange); ok {
+			return x.TimestampRange
+		}
+	}
+	return nil
+}
+
+func (x *ConstraintTest) GetTimestampLtNow() *timestamppb.Timestamp {
+	if x != nil {
+		if x, ok := x.TestCase.(*ConstraintTest_TimestampLtNow); ok {
+			return x.TimestampLtNow
+		}
+	}
+	return nil
+}
+
+func (x *ConstraintTest) GetAnyIn() *anypb.Any {
+	if x != nil {
+		if x, ok := x.TestCase.(*ConstraintTest_AnyIn); ok {
+			return x.AnyIn
+		}
+	}
+	return nil
+}
+
+func (x *ConstraintTest) GetAnyNotIn() *anypb.Any {
+	if x != nil {
+		if x, ok := x.TestCase.(*ConstraintTest_AnyNotIn); ok {
+			return x.AnyNotIn
+		}
+	}
+	return nil
+}
+
 func (x *ConstraintTest) GetConstBool() bool {
 	if x != nil {
 		if x, ok := x.TestCase.(*ConstraintTest_ConstBool); ok {
@@ -1317,6 +1443,27 @@ func (x *ConstraintTest) GetInMap() map[string]string {
 	return nil
 }
 
+func (x *ConstraintTest) GetCelList() []string {
+	if x != nil {
+		return x.CelList
+	}
+	return nil
+}
+
+func (x *ConstraintTest) GetUniqueList() []string {
+	if x != nil {
+		return x.UniqueList
+	}
+	return nil
+}
+
+func (x *ConstraintTest) GetPairsMap() map[string]int32 {
+	if x != nil {
+		return x.PairsMap
+	}
+	return nil
+}
+
 func (x *ConstraintTest) GetIsList() []string {
 	if x != nil {
 		return x.IsList
@@ -1336,6 +1483,54 @@ type ConstraintTest_RequiredOptional_ struct {
 	RequiredOptional *ConstraintTest_RequiredOptional `protobuf:"bytes,2,opt,name=required_optional,json=requiredOptional,proto3,oneof"`
 }
 
+type ConstraintTest_MessageOneof_ struct {
+	MessageOneof *ConstraintTest_MessageOneof `protobuf:"bytes,126,opt,name=message_oneof,json=messageOneof,proto3,oneof"`
+}
+
+type ConstraintTest_CelRules_ struct {
+	CelRules *ConstraintTest_CelRules `protobuf:"bytes,127,opt,name=cel_rules,json=celRules,proto3,oneof"`
+}
+
+type ConstraintTest_CelInt struct {
+	CelInt int32 `protobuf:"varint,128,opt,name=cel_int,json=celInt,proto3,oneof"`
+}
+
+type ConstraintTest_CelString struct {
+	CelString string `protobuf:"bytes,129,opt,name=cel_string,json=celString,proto3,oneof"`
+}
+
+type ConstraintTest_CelIn struct {
+	CelIn string `protobuf:"bytes,130,opt,name=cel_in,json=celIn,proto3,oneof"`
+}
+
+type ConstraintTest_CelUnknown struct {
+	CelUnknown string `protobuf:"bytes,131,opt,name=cel_unknown,json=celUnknown,proto3,oneof"`
+}
+
+type ConstraintTest_DurationRange struct {
+	DurationRange *durationpb.Duration `protobuf:"bytes,135,opt,name=duration_range,json=durationRange,proto3,oneof"`
+}
+
+type ConstraintTest_DurationIn struct {
+	DurationIn *durationpb.Duration `protobuf:"bytes,136,opt,name=duration_in,json=durationIn,proto3,oneof"`
+}
+
+type ConstraintTest_TimestampRange struct {
+	TimestampRange *timestamppb.Timestamp `protobuf:"bytes,137,opt,name=timestamp_range,json=timestampRange,proto3,oneof"`
+}
+
+type ConstraintTest_TimestampLtNow struct {
+	TimestampLtNow *timestamppb.Timestamp `protobuf:"bytes,138,opt,name=timestamp_lt_now,json=timestampLtNow,proto3,oneof"`
+}
+
+type ConstraintTest_AnyIn struct {
+	AnyIn *anypb.Any `protobuf:"bytes,139,opt,name=any_in,json=anyIn,proto3,oneof"`
+}
+
+type ConstraintTest_AnyNotIn struct {
+	AnyNotIn *anypb.Any `protobuf:"bytes,140,opt,name=any_not_in,json=anyNotIn,proto3,oneof"`
+}
+
 type ConstraintTest_ConstBool struct {
 	ConstBool bool `protobuf:"varint,3,opt,name=const_bool,json=constBool,proto3,oneof"`
 }
@@ -1712,6 +1907,30 @@ func (*ConstraintTest_RequiredImplicit_) isConstraintTest_TestCase() {}
 
 func (*ConstraintTest_RequiredOptional_) isConstraintTest_TestCase() {}
 
+func (*ConstraintTest_MessageOneof_) isConstraintTest_TestCase() {}
+
+func (*ConstraintTest_CelRules_) isConstraintTest_TestCase() {}
+
+func (*ConstraintTest_CelInt) isConstraintTest_TestCase() {}
+
+func (*ConstraintTest_CelString) isConstraintTest_TestCase() {}
+
+func (*ConstraintTest_CelIn) isConstraintTest_TestCase() {}
+
+func (*ConstraintTest_CelUnknown) isConstraintTest_TestCase() {}
+
+func (*ConstraintTest_DurationRange) isConstraintTest_TestCase() {}
+
+func (*ConstraintTest_DurationIn) isConstraintTest_TestCase() {}
+
+func (*ConstraintTest_TimestampRange) isConstraintTest_TestCase() {}
+
+func (*ConstraintTest_TimestampLtNow) isConstraintTest_TestCase() {}
+
+func (*ConstraintTest_AnyIn) isConstraintTest_TestCase() {}
+
+func (*ConstraintTest_AnyNotIn) isConstraintTest_TestCase() {}
+
 func (*ConstraintTest_ConstBool) isConstraintTest_TestCase() {}
 
 func (*ConstraintTest_ConstEnum) isConstraintTest_TestCase() {}
@@ -2078,14 +2297,188 @@ func (x *ConstraintTest_RequiredOptional) GetStrictEnumValue() ConstraintTest_En
 	return ConstraintTest_ENUM_UNSPECIFIED
 }
 
+type ConstraintTest_MessageOneof struct {
+	state         protoimpl.MessageState           `protogen:"open.v1"`
+	StringValue   string                           `protobuf:"bytes,1,opt,name=string_value,json=stringValue,proto3" json:"string_value,omitempty"`
+	IntValue      int32                            `protobuf:"varint,2,opt,name=int_value,json=intValue,proto3" json:"int_value,omitempty"`
+	ListValue     []string                         `protobuf:"bytes,3,rep,name=list_value,json=listValue,proto3" json:"list_value,omitempty"`
+	OptionalValue *bool                            `protobuf:"varint,4,opt,name=optional_value,json=optionalValue,proto3,oneof" json:"optional_value,omitempty"`
+	MessageValue  *ConstraintTest_RequiredImplicit `protobuf:"bytes,5,opt,name=message_value,json=messageValue,proto3" json:"message_value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConstraintTest_MessageOneof) Reset() {
+	*x = ConstraintTest_MessageOneof{}
+	mi := &file_buf_protoschema_test_v1_constraints_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConstraintTest_MessageOneof) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConstraintTest_MessageOneof) ProtoMessage() {}
+
+func (x *ConstraintTest_MessageOneof) ProtoReflect() protoreflect.Message {
+	mi := &file_buf_protoschema_test_v1_constraints_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConstraintTest_MessageOneof.ProtoReflect.Descriptor instead.
+func (*ConstraintTest_MessageOneof) Descriptor() ([]byte, []int) {
+	return file_buf_protoschema_test_v1_constraints_proto_rawDescGZIP(), []int{0, 2}
+}
+
+func (x *ConstraintTest_MessageOneof) GetStringValue() string {
+	if x != nil {
+		return x.StringValue
+	}
+	return ""
+}
+
+func (x *ConstraintTest_MessageOneof) GetIntValue() int32 {
+	if x != nil {
+		return x.IntValue
+	}
+	return 0
+}
+
+func (x *ConstraintTest_MessageOneof) GetListValue() []string {
+	if x != nil {
+		return x.ListValue
+	}
+	return nil
+}
+
+func (x *ConstraintTest_MessageOneof) GetOptionalValue() bool {
+	if x != nil && x.OptionalValue != nil {
+		return *x.OptionalValue
+	}
+	return false
+}
+
+func (x *ConstraintTest_MessageOneof) GetMessageValue() *ConstraintTest_RequiredImplicit {
+	if x != nil {
+		return x.MessageValue
+	}
+	return nil
+}
+
+type ConstraintTest_CelRules struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	A             *string                `protobuf:"bytes,1,opt,name=a,proto3,oneof" json:"a,omitempty"`
+	B             *string                `protobuf:"bytes,2,opt,name=b,proto3,oneof" json:"b,omitempty"`
+	C             string                 `protobuf:"bytes,3,opt,name=c,proto3" json:"c,omitempty"`
+	Min           int32                  `protobuf:"varint,4,opt,name=min,proto3" json:"min,omitempty"`
+	Max           int32                  `protobuf:"varint,5,opt,name=max,proto3" json:"max,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ConstraintTest_CelRules) Reset() {
+	*x = ConstraintTest_CelRules{}
+	mi := &file_buf_protoschema_test_v1_constraints_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ConstraintTest_CelRules) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ConstraintTest_CelRules) ProtoMessage() {}
+
+func (x *ConstraintTest_CelRules) ProtoReflect() protoreflect.Message {
+	mi := &file_buf_protoschema_test_v1_constraints_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ConstraintTest_CelRules.ProtoReflect.Descriptor instead.
+func (*ConstraintTest_CelRules) Descriptor() ([]byte, []int) {
+	return file_buf_protoschema_test_v1_constraints_proto_rawDescGZIP(), []int{0, 3}
+}
+
+func (x *ConstraintTest_CelRules) GetA() string {
+	if x != nil && x.A != nil {
+		return *x.A
+	}
+	return ""
+}
+
+func (x *ConstraintTest_CelRules) GetB() string {
+	if x != nil && x.B != nil {
+		return *x.B
+	}
+	return ""
+}
+
+func (x *ConstraintTest_CelRules) GetC() string {
+	if x != nil {
+		return x.C
+	}
+	return ""
+}
+
+func (x *ConstraintTest_CelRules) GetMin() int32 {
+	if x != nil {
+		return x.Min
+	}
+	return 0
+}
+
+func (x *ConstraintTest_CelRules) GetMax() int32 {
+	if x != nil {
+		return x.Max
+	}
+	return 0
+}
+
 var File_buf_protoschema_test_v1_constraints_proto protoreflect.FileDescriptor
 
 const file_buf_protoschema_test_v1_constraints_proto_rawDesc = "" +
 	"\n" +
-	")buf/protoschema/test/v1/constraints.proto\x12\x17buf.protoschema.test.v1\x1a\x1bbuf/validate/validate.proto\"\xa19\n" +
+	")buf/protoschema/test/v1/constraints.proto\x12\x17buf.protoschema.test.v1\x1a\x1bbuf/validate/validate.proto\x1a\x19google/protobuf/any.proto\x1a\x1egoogle/protobuf/duration.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"\xbaK\n" +
 	"\x0eConstraintTest\x12g\n" +
 	"\x11required_implicit\x18\x01 \x01(\v28.buf.protoschema.test.v1.ConstraintTest.RequiredImplicitH\x00R\x10requiredImplicit\x12g\n" +
-	"\x11required_optional\x18\x02 \x01(\v28.buf.protoschema.test.v1.ConstraintTest.RequiredOptionalH\x00R\x10requiredOptional\x12(\n" +
+	"\x11required_optional\x18\x02 \x01(\v28.buf.protoschema.test.v1.ConstraintTest.RequiredOptionalH\x00R\x10requiredOptional\x12[\n" +
+	"\rmessage_oneof\x18~ \x01(\v24.buf.protoschema.test.v1.ConstraintTest.MessageOneofH\x00R\fmessageOneof\x12O\n" +
+	"\tcel_rules\x18\x7f \x01(\v20.buf.protoschema.test.v1.ConstraintTest.CelRulesH\x00R\bcelRules\x12^\n" +
+	"\acel_int\x18\x80\x01 \x01(\x05BB\xbaH?\xba\x01<\n" +
+	"\acel_int\x12\x18must be between 1 and 10\x1a\x17this >= 1 && this <= 10H\x00R\x06celInt\x12\x88\x01\n" +
+	"\n" +
+	"cel_string\x18\x81\x01 \x01(\tBf\xbaHc\xba\x01`\n" +
+	"\n" +
+	"cel_string\x12&must be fewer than 5 lowercase letters\x1a*size(this) < 5 && this.matches('^[a-z]+$')H\x00R\tcelString\x12T\n" +
+	"\x06cel_in\x18\x82\x01 \x01(\tB:\xbaH7\xba\x014\n" +
+	"\x06cel_in\x1a*this in ['a', 'b'] ? '' : 'must be a or b'H\x00R\x05celIn\x12M\n" +
+	"\vcel_unknown\x18\x83\x01 \x01(\tB)\xbaH&\xba\x01#\n" +
+	"\vcel_unknown\x1a\x14this.startsWith('a')H\x00R\n" +
+	"celUnknown\x12S\n" +
+	"\x0eduration_range\x18\x87\x01 \x01(\v2\x19.google.protobuf.DurationB\x0e\xbaH\v\xaa\x01\b\x1a\x02\b<2\x02\b\x01H\x00R\rdurationRange\x12S\n" +
+	"\vduration_in\x18\x88\x01 \x01(\v2\x19.google.protobuf.DurationB\x14\xbaH\x11\xaa\x01\x0e:\x02\b\x01:\b\b\x01\x10\x80ʵ\xee\x01H\x00R\n" +
+	"durationIn\x12X\n" +
+	"\x0ftimestamp_range\x18\x89\x01 \x01(\v2\x1a.google.protobuf.TimestampB\x10\xbaH\r\xb2\x01\n" +
+	"\"\x06\b\x80\xae\x99\xa4\x0f*\x00H\x00R\x0etimestampRange\x12Q\n" +
+	"\x10timestamp_lt_now\x18\x8a\x01 \x01(\v2\x1a.google.protobuf.TimestampB\b\xbaH\x05\xb2\x01\x028\x01H\x00R\x0etimestampLtNow\x12\x8a\x02\n" +
+	"\x06any_in\x18\x8b\x01 \x01(\v2\x14.google.protobuf.AnyB\xd9\x01\xbaH\xd5\x01\xa2\x01\xd1\x01\x12Ctype.googleapis.com/buf.protoschema.test.v1.ConstraintTest.CelRules\x12,type.googleapis.com/google.protobuf.Duration\x12-type.googleapis.com/google.protobuf.Timestamp\x1a-type.googleapis.com/google.protobuf.TimestampH\x00R\x05anyIn\x12k\n" +
+	"\n" +
+	"any_not_in\x18\x8c\x01 \x01(\v2\x14.google.protobuf.AnyB4\xbaH1\xa2\x01.\x1a,type.googleapis.com/google.protobuf.DurationH\x00R\banyNotIn\x12(\n" +
 	"\n" +
 	"const_bool\x18\x03 \x01(\bB\a\xbaH\x04j\x02\b\x00H\x00R\tconstBool\x12W\n" +
 	"\n" +
@@ -2292,7 +2685,15 @@ const file_buf_protoschema_test_v1_constraints_proto_rawDesc = "" +
 	"\vlt_gt_float\x18q \x01(\x02B\x0f\xbaH\f\n" +
 	"\n" +
 	"\x15\x00\x00\x80?%\x00\x00\xa0@R\tltGtFloat\x12u\n" +
-	"\x06in_map\x18v \x03(\v22.buf.protoschema.test.v1.ConstraintTest.InMapEntryB*\xbaH'\x9a\x01$\"\x0er\fR\x04key1R\x04key2*\x12r\x10R\x06value1R\x06value2R\x05inMap\x12#\n" +
+	"\x06in_map\x18v \x03(\v22.buf.protoschema.test.v1.ConstraintTest.InMapEntryB*\xbaH'\x9a\x01$\"\x0er\fR\x04key1R\x04key2*\x12r\x10R\x06value1R\x06value2R\x05inMap\x12a\n" +
+	"\bcel_list\x18\x84\x01 \x03(\tBE\xbaHB\xba\x01\x1b\n" +
+	"\bcel_list\x1a\x0fsize(this) <= 2\x92\x01!\"\x1f\xba\x01\x1c\n" +
+	"\x0ecel_list.items\x1a\n" +
+	"this != ''R\acelList\x12*\n" +
+	"\vunique_list\x18\x85\x01 \x03(\tB\b\xbaH\x05\x92\x01\x02\x18\x01R\n" +
+	"uniqueList\x12_\n" +
+	"\tpairs_map\x18\x86\x01 \x03(\v25.buf.protoschema.test.v1.ConstraintTest.PairsMapEntryB\n" +
+	"\xbaH\a\x9a\x01\x04\b\x01\x10\x02R\bpairsMap\x12#\n" +
 	"\ais_list\x18} \x03(\tB\n" +
 	"\xbaH\a\x92\x01\x04\b\x01\x10\n" +
 	"R\x06isList\x1a\xa0\x02\n" +
@@ -2313,11 +2714,39 @@ const file_buf_protoschema_test_v1_constraints_proto_rawDesc = "" +
 	"\v_bool_valueB\x0f\n" +
 	"\r_string_valueB\r\n" +
 	"\v_enum_valueB\x14\n" +
-	"\x12_strict_enum_value\x1a8\n" +
+	"\x12_strict_enum_value\x1a\xda\x02\n" +
+	"\fMessageOneof\x12!\n" +
+	"\fstring_value\x18\x01 \x01(\tR\vstringValue\x12\x1b\n" +
+	"\tint_value\x18\x02 \x01(\x05R\bintValue\x12\x1d\n" +
+	"\n" +
+	"list_value\x18\x03 \x03(\tR\tlistValue\x12*\n" +
+	"\x0eoptional_value\x18\x04 \x01(\bH\x00R\roptionalValue\x88\x01\x01\x12]\n" +
+	"\rmessage_value\x18\x05 \x01(\v28.buf.protoschema.test.v1.ConstraintTest.RequiredImplicitR\fmessageValue:M\xbaHJ\"'\n" +
+	"\fstring_value\n" +
+	"\tint_value\n" +
+	"\n" +
+	"list_value\x10\x01\"\x1f\n" +
+	"\x0eoptional_value\n" +
+	"\rmessage_valueB\x11\n" +
+	"\x0f_optional_value\x1a\xfd\x02\n" +
+	"\bCelRules\x12\x11\n" +
+	"\x01a\x18\x01 \x01(\tH\x00R\x01a\x88\x01\x01\x12\x11\n" +
+	"\x01b\x18\x02 \x01(\tH\x01R\x01b\x88\x01\x01\x12\f\n" +
+	"\x01c\x18\x03 \x01(\tR\x01c\x12\x10\n" +
+	"\x03min\x18\x04 \x01(\x05R\x03min\x12\x10\n" +
+	"\x03max\x18\x05 \x01(\x05R\x03max:\x8c\x02\xbaH\x88\x02\x1aM\n" +
+	"\x11cel_rules.implies\x12\x1bb is required when a is set\x1a\x1b!has(this.a) || has(this.b)\x1aj\n" +
+	"\x16cel_rules.implies_size\x12-c must be at least 3 characters when a is set\x1a!!has(this.a) || size(this.c) >= 3\x1aK\n" +
+	"\x11cel_rules.ordered\x12 min must not be greater than max\x1a\x14this.min <= this.maxB\x04\n" +
+	"\x02_aB\x04\n" +
+	"\x02_b\x1a8\n" +
 	"\n" +
 	"InMapEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"I\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\x1a;\n" +
+	"\rPairsMapEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x05R\x05value:\x028\x01\"I\n" +
 	"\x04Enum\x12\x14\n" +
 	"\x10ENUM_UNSPECIFIED\x10\x00\x12\r\n" +
 	"\tENUM_VAL1\x10\x01\x12\r\n" +
@@ -2342,35 +2771,51 @@ func file_buf_protoschema_test_v1_constraints_proto_rawDescGZIP() []byte {
 }
 
 var file_buf_protoschema_test_v1_constraints_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
-var file_buf_protoschema_test_v1_constraints_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_buf_protoschema_test_v1_constraints_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
 var file_buf_protoschema_test_v1_constraints_proto_goTypes = []any{
 	(ConstraintTest_Enum)(0),                // 0: buf.protoschema.test.v1.ConstraintTest.Enum
 	(*ConstraintTest)(nil),                  // 1: buf.protoschema.test.v1.ConstraintTest
 	(*ConstraintTests)(nil),                 // 2: buf.protoschema.test.v1.ConstraintTests
 	(*ConstraintTest_RequiredImplicit)(nil), // 3: buf.protoschema.test.v1.ConstraintTest.RequiredImplicit
 	(*ConstraintTest_RequiredOptional)(nil), // 4: buf.protoschema.test.v1.ConstraintTest.RequiredOptional
-	nil,                                     // 5: buf.protoschema.test.v1.ConstraintTest.InMapEntry
+	(*ConstraintTest_MessageOneof)(nil),     // 5: buf.protoschema.test.v1.ConstraintTest.MessageOneof
+	(*ConstraintTest_CelRules)(nil),         // 6: buf.protoschema.test.v1.ConstraintTest.CelRules
+	nil,                                     // 7: buf.protoschema.test.v1.ConstraintTest.InMapEntry
+	nil,                                     // 8: buf.protoschema.test.v1.ConstraintTest.PairsMapEntry
+	(*durationpb.Duration)(nil),             // 9: google.protobuf.Duration
+	(*timestamppb.Timestamp)(nil),           // 10: google.protobuf.Timestamp
+	(*anypb.Any)(nil),                       // 11: google.protobuf.Any
 }
 var file_buf_protoschema_test_v1_constraints_proto_depIdxs = []int32{
 	3,  // 0: buf.protoschema.test.v1.ConstraintTest.required_implicit:type_name -> buf.protoschema.test.v1.ConstraintTest.RequiredImplicit
 	4,  // 1: buf.protoschema.test.v1.ConstraintTest.required_optional:type_name -> buf.protoschema.test.v1.ConstraintTest.RequiredOptional
-	0,  // 2: buf.protoschema.test.v1.ConstraintTest.const_enum:type_name -> buf.protoschema.test.v1.ConstraintTest.Enum
-	0,  // 3: buf.protoschema.test.v1.ConstraintTest.defined_only_enum:type_name -> buf.protoschema.test.v1.ConstraintTest.Enum
-	0,  // 4: buf.protoschema.test.v1.ConstraintTest.in_enum:type_name -> buf.protoschema.test.v1.ConstraintTest.Enum
-	0,  // 5: buf.protoschema.test.v1.ConstraintTest.not_in_enum:type_name -> buf.protoschema.test.v1.ConstraintTest.Enum
-	0,  // 6: buf.protoschema.test.v1.ConstraintTest.defined_only_not_in_enum:type_name -> buf.protoschema.test.v1.ConstraintTest.Enum
-	0,  // 7: buf.protoschema.test.v1.ConstraintTest.in_and_not_in_enum:type_name -> buf.protoschema.test.v1.ConstraintTest.Enum
-	5,  // 8: buf.protoschema.test.v1.ConstraintTest.in_map:type_name -> buf.protoschema.test.v1.ConstraintTest.InMapEntry
-	1,  // 9: buf.protoschema.test.v1.ConstraintTests.test_cases:type_name -> buf.protoschema.test.v1.ConstraintTest
-	0,  // 10: buf.protoschema.test.v1.ConstraintTest.RequiredImplicit.enum_value:type_name -> buf.protoschema.test.v1.ConstraintTest.Enum
-	0,  // 11: buf.protoschema.test.v1.ConstraintTest.RequiredImplicit.strict_enum_value:type_name -> buf.protoschema.test.v1.ConstraintTest.Enum
-	0,  // 12: buf.protoschema.test.v1.ConstraintTest.RequiredOptional.enum_value:type_name -> buf.protoschema.test.v1.ConstraintTest.Enum
-	0,  // 13: buf.protoschema.test.v1.ConstraintTest.RequiredOptional.strict_enum_value:type_name -> buf.protoschema.test.v1.ConstraintTest.Enum
-	14, // [14:14] is the sub-list for method output_type
-	14, // [14:14] is the sub-list for method input_type
-	14, // [14:14] is the sub-list for extension type_name
-	14, // [14:14] is the sub-list for extension extendee
-	0,  // [0:14] is the sub-list for field type_name
+	5,  // 2: buf.protoschema.test.v1.ConstraintTest.message_oneof:type_name -> buf.protoschema.test.v1.ConstraintTest.MessageOneof
+	6,  // 3: buf.protoschema.test.v1.ConstraintTest.cel_rules:type_name -> buf.protoschema.test.v1.ConstraintTest.CelRules
+	9,  // 4: buf.protoschema.test.v1.ConstraintTest.duration_range:type_name -> google.protobuf.Duration
+	9,  // 5: buf.protoschema.test.v1.ConstraintTest.duration_in:type_name -> google.protobuf.Duration
+	10, // 6: buf.protoschema.test.v1.ConstraintTest.timestamp_range:type_name -> google.protobuf.Timestamp
+	10, // 7: buf.protoschema.test.v1.ConstraintTest.timestamp_lt_now:type_name -> google.protobuf.Timestamp
+	11, // 8: buf.protoschema.test.v1.ConstraintTest.any_in:type_name -> google.protobuf.Any
+	11, // 9: buf.protoschema.test.v1.ConstraintTest.any_not_in:type_name -> google.protobuf.Any
+	0,  // 10: buf.protoschema.test.v1.ConstraintTest.const_enum:type_name -> buf.protoschema.test.v1.ConstraintTest.Enum
+	0,  // 11: buf.protoschema.test.v1.ConstraintTest.defined_only_enum:type_name -> buf.protoschema.test.v1.ConstraintTest.Enum
+	0,  // 12: buf.protoschema.test.v1.ConstraintTest.in_enum:type_name -> buf.protoschema.test.v1.ConstraintTest.Enum
+	0,  // 13: buf.protoschema.test.v1.ConstraintTest.not_in_enum:type_name -> buf.protoschema.test.v1.ConstraintTest.Enum
+	0,  // 14: buf.protoschema.test.v1.ConstraintTest.defined_only_not_in_enum:type_name -> buf.protoschema.test.v1.ConstraintTest.Enum
+	0,  // 15: buf.protoschema.test.v1.ConstraintTest.in_and_not_in_enum:type_name -> buf.protoschema.test.v1.ConstraintTest.Enum
+	7,  // 16: buf.protoschema.test.v1.ConstraintTest.in_map:type_name -> buf.protoschema.test.v1.ConstraintTest.InMapEntry
+	8,  // 17: buf.protoschema.test.v1.ConstraintTest.pairs_map:type_name -> buf.protoschema.test.v1.ConstraintTest.PairsMapEntry
+	1,  // 18: buf.protoschema.test.v1.ConstraintTests.test_cases:type_name -> buf.protoschema.test.v1.ConstraintTest
+	0,  // 19: buf.protoschema.test.v1.ConstraintTest.RequiredImplicit.enum_value:type_name -> buf.protoschema.test.v1.ConstraintTest.Enum
+	0,  // 20: buf.protoschema.test.v1.ConstraintTest.RequiredImplicit.strict_enum_value:type_name -> buf.protoschema.test.v1.ConstraintTest.Enum
+	0,  // 21: buf.protoschema.test.v1.ConstraintTest.RequiredOptional.enum_value:type_name -> buf.protoschema.test.v1.ConstraintTest.Enum
+	0,  // 22: buf.protoschema.test.v1.ConstraintTest.RequiredOptional.strict_enum_value:type_name -> buf.protoschema.test.v1.ConstraintTest.Enum
+	3,  // 23: buf.protoschema.test.v1.ConstraintTest.MessageOneof.message_value:type_name -> buf.protoschema.test.v1.ConstraintTest.RequiredImplicit
+	24, // [24:24] is the sub-list for method output_type
+	24, // [24:24] is the sub-list for method input_type
+	24, // [24:24] is the sub-list for extension type_name
+	24, // [24:24] is the sub-list for extension extendee
+	0,  // [0:24] is the sub-list for field type_name
 }
 
 func init() { file_buf_protoschema_test_v1_constraints_proto_init() }
@@ -2381,6 +2826,18 @@ func file_buf_protoschema_test_v1_constraints_proto_init() {
 	file_buf_protoschema_test_v1_constraints_proto_msgTypes[0].OneofWrappers = []any{
 		(*ConstraintTest_RequiredImplicit_)(nil),
 		(*ConstraintTest_RequiredOptional_)(nil),
+		(*ConstraintTest_MessageOneof_)(nil),
+		(*ConstraintTest_CelRules_)(nil),
+		(*ConstraintTest_CelInt)(nil),
+		(*ConstraintTest_CelString)(nil),
+		(*ConstraintTest_CelIn)(nil),
+		(*ConstraintTest_CelUnknown)(nil),
+		(*ConstraintTest_DurationRange)(nil),
+		(*ConstraintTest_DurationIn)(nil),
+		(*ConstraintTest_TimestampRange)(nil),
+		(*ConstraintTest_TimestampLtNow)(nil),
+		(*ConstraintTest_AnyIn)(nil),
+		(*ConstraintTest_AnyNotIn)(nil),
 		(*ConstraintTest_ConstBool)(nil),
 		(*ConstraintTest_ConstEnum)(nil),
 		(*ConstraintTest_DefinedOnlyEnum)(nil),
@@ -2476,13 +2933,15 @@ func file_buf_protoschema_test_v1_constraints_proto_init() {
 		(*ConstraintTest_InSfixed64)(nil),
 	}
 	file_buf_protoschema_test_v1_constraints_proto_msgTypes[3].OneofWrappers = []any{}
+	file_buf_protoschema_test_v1_constraints_proto_msgTypes[4].OneofWrappers = []any{}
+	file_buf_protoschema_test_v1_constraints_proto_msgTypes[5].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_buf_protoschema_test_v1_constraints_proto_rawDesc), len(file_buf_protoschema_test_v1_constraints_proto_rawDesc)),
 			NumEnums:      1,
-			NumMessages:   5,
+			NumMessages:   8,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
@@ -22,6 +22,7 @@ package testv1
 
 import (
 	_ "buf.build/gen/go/bufbuild/protovalidate/protocolbuffers/go/buf/validate"
+	_ "github.com/bufbuild/protoschema-plugins/internal/gen/proto/buf/protoschema/v1"
 	proto3 "github.com/bufbuild/protoschema-plugins/internal/gen/proto/bufext/cel/expr/conformance/proto3"
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
@@ -37,6 +38,62 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type SchemaOptions_Status int32
+
+const (
+	SchemaOptions_STATUS_UNSPECIFIED SchemaOptions_Status = 0
+	SchemaOptions_STATUS_ACTIVE      SchemaOptions_Status = 1
+	SchemaOptions_STATUS_LEGACY      SchemaOptions_Status = 2
+	SchemaOptions_STATUS_REMOVED     SchemaOptions_Status = 3
+	// Deprecated: Marked as deprecated in buf/protoschema/test/v1/test_cases.proto.
+	SchemaOptions_STATUS_OBSOLETE SchemaOptions_Status = 4
+)
+
+// Enum value maps for SchemaOptions_Status.
+var (
+	SchemaOptions_Status_name = map[int32]string{
+		0: "STATUS_UNSPECIFIED",
+		1: "STATUS_ACTIVE",
+		2: "STATUS_LEGACY",
+		3: "STATUS_REMOVED",
+		4: "STATUS_OBSOLETE",
+	}
+	SchemaOptions_Status_value = map[string]int32{
+		"STATUS_UNSPECIFIED": 0,
+		"STATUS_ACTIVE":      1,
+		"STATUS_LEGACY":      2,
+		"STATUS_REMOVED":     3,
+		"STATUS_OBSOLETE":    4,
+	}
+)
+
+func (x SchemaOptions_Status) Enum() *SchemaOptions_Status {
+	p := new(SchemaOptions_Status)
+	*p = x
+	return p
+}
+
+func (x SchemaOptions_Status) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (SchemaOptions_Status) Descriptor() protoreflect.EnumDescriptor {
+	return file_buf_protoschema_test_v1_test_cases_proto_enumTypes[0].Descriptor()
+}
+
+func (SchemaOptions_Status) Type() protoreflect.EnumType {
+	return &file_buf_protoschema_test_v1_test_cases_proto_enumTypes[0]
+}
+
+func (x SchemaOptions_Status) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use SchemaOptions_Status.Descriptor instead.
+func (SchemaOptions_Status) EnumDescriptor() ([]byte, []int) {
+	return file_buf_protoschema_test_v1_test_cases_proto_rawDescGZIP(), []int{4, 0}
+}
+
 type NestedReference struct {
 	state         protoimpl.MessageState             `protogen:"open.v1"`
 	NestedMessage *proto3.TestAllTypes_NestedMessage `protobuf:"bytes,1,opt,name=nested_message,json=nestedMessage,proto3" json:"nested_message,omitempty"`
@@ -241,11 +298,364 @@ func (x *IgnoreField) GetNestedReference() *NestedReference {
 	return nil
 }
 
+type EntryPoint struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Request       *EntryPoint_Request    `protobuf:"bytes,1,opt,name=request,proto3" json:"request,omitempty"`
+	Response      *EntryPoint_Response   `protobuf:"bytes,2,opt,name=response,proto3" json:"response,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EntryPoint) Reset() {
+	*x = EntryPoint{}
+	mi := &file_buf_protoschema_test_v1_test_cases_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EntryPoint) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EntryPoint) ProtoMessage() {}
+
+func (x *EntryPoint) ProtoReflect() protoreflect.Message {
+	mi := &file_buf_protoschema_test_v1_test_cases_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EntryPoint.ProtoReflect.Descriptor instead.
+func (*EntryPoint) Descriptor() ([]byte, []int) {
+	return file_buf_protoschema_test_v1_test_cases_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *EntryPoint) GetRequest() *EntryPoint_Request {
+	if x != nil {
+		return x.Request
+	}
+	return nil
+}
+
+func (x *EntryPoint) GetResponse() *EntryPoint_Response {
+	if x != nil {
+		return x.Response
+	}
+	return nil
+}
+
+// A test case for the options in the buf.protoschema package.
+// Schema options.
+//
+// Overridden by the message options.
+type SchemaOptions struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Name     string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	MaxCount int32                  `protobuf:"varint,2,opt,name=max_count,json=maxCount,proto3" json:"max_count,omitempty"`
+	// The comment title.
+	//
+	// The comment description.
+	Id           string `protobuf:"bytes,3,opt,name=id,proto3" json:"id,omitempty"`
+	Password     string `protobuf:"bytes,4,opt,name=password,proto3" json:"password,omitempty"`
+	LegacyName   string `protobuf:"bytes,5,opt,name=legacy_name,json=legacyName,proto3" json:"legacy_name,omitempty"`
+	InternalName string `protobuf:"bytes,6,opt,name=internal_name,json=internalName,proto3" json:"internal_name,omitempty"`
+	// Mentions jsonschema:ignore, but is visible.
+	Note   string               `protobuf:"bytes,7,opt,name=note,proto3" json:"note,omitempty"`
+	Status SchemaOptions_Status `protobuf:"varint,8,opt,name=status,proto3,enum=buf.protoschema.test.v1.SchemaOptions_Status" json:"status,omitempty"`
+	Links  []string             `protobuf:"bytes,9,rep,name=links,proto3" json:"links,omitempty"`
+	// Types that are valid to be assigned to Contact:
+	//
+	//	*SchemaOptions_Email
+	//	*SchemaOptions_Phone
+	Contact isSchemaOptions_Contact `protobuf_oneof:"contact"`
+	// Types that are valid to be assigned to LegacyContact:
+	//
+	//	*SchemaOptions_Fax
+	LegacyContact isSchemaOptions_LegacyContact `protobuf_oneof:"legacy_contact"`
+	Nickname      *string                       `protobuf:"bytes,13,opt,name=nickname,proto3,oneof" json:"nickname,omitempty"`
+	// Deprecated: Marked as deprecated in buf/protoschema/test/v1/test_cases.proto.
+	OldName       string `protobuf:"bytes,14,opt,name=old_name,json=oldName,proto3" json:"old_name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SchemaOptions) Reset() {
+	*x = SchemaOptions{}
+	mi := &file_buf_protoschema_test_v1_test_cases_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SchemaOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SchemaOptions) ProtoMessage() {}
+
+func (x *SchemaOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_buf_protoschema_test_v1_test_cases_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SchemaOptions.ProtoReflect.Descriptor instead.
+func (*SchemaOptions) Descriptor() ([]byte, []int) {
+	return file_buf_protoschema_test_v1_test_cases_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *SchemaOptions) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SchemaOptions) GetMaxCount() int32 {
+	if x != nil {
+		return x.MaxCount
+	}
+	return 0
+}
+
+func (x *SchemaOptions) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *SchemaOptions) GetPassword() string {
+	if x != nil {
+		return x.Password
+	}
+	return ""
+}
+
+func (x *SchemaOptions) GetLegacyName() string {
+	if x != nil {
+		return x.LegacyName
+	}
+	return ""
+}
+
+func (x *SchemaOptions) GetInternalName() string {
+	if x != nil {
+		return x.InternalName
+	}
+	return ""
+}
+
+func (x *SchemaOptions) GetNote() string {
+	if x != nil {
+		return x.Note
+	}
+	return ""
+}
+
+func (x *SchemaOptions) GetStatus() SchemaOptions_Status {
+	if x != nil {
+		return x.Status
+	}
+	return SchemaOptions_STATUS_UNSPECIFIED
+}
+
+func (x *SchemaOptions) GetLinks() []string {
+	if x != nil {
+		return x.Links
+	}
+	return nil
+}
+
+func (x *SchemaOptions) GetContact() isSchemaOptions_Contact {
+	if x != nil {
+		return x.Contact
+	}
+	return nil
+}
+
+func (x *SchemaOptions) GetEmail() string {
+	if x != nil {
+		if x, ok := x.Contact.(*SchemaOptions_Email); ok {
+			return x.Email
+		}
+	}
+	return ""
+}
+
+func (x *SchemaOptions) GetPhone() string {
+	if x != nil {
+		if x, ok := x.Contact.(*SchemaOptions_Phone); ok {
+			return x.Phone
+		}
+	}
+	return ""
+}
+
+func (x *SchemaOptions) GetLegacyContact() isSchemaOptions_LegacyContact {
+	if x != nil {
+		return x.LegacyContact
+	}
+	return nil
+}
+
+func (x *SchemaOptions) GetFax() string {
+	if x != nil {
+		if x, ok := x.LegacyContact.(*SchemaOptions_Fax); ok {
+			return x.Fax
+		}
+	}
+	return ""
+}
+
+func (x *SchemaOptions) GetNickname() string {
+	if x != nil && x.Nickname != nil {
+		return *x.Nickname
+	}
+	return ""
+}
+
+// Deprecated: Marked as deprecated in buf/protoschema/test/v1/test_cases.proto.
+func (x *SchemaOptions) GetOldName() string {
+	if x != nil {
+		return x.OldName
+	}
+	return ""
+}
+
+type isSchemaOptions_Contact interface {
+	isSchemaOptions_Contact()
+}
+
+type SchemaOptions_Email struct {
+	Email string `protobuf:"bytes,10,opt,name=email,proto3,oneof"`
+}
+
+type SchemaOptions_Phone struct {
+	Phone string `protobuf:"bytes,11,opt,name=phone,proto3,oneof"`
+}
+
+func (*SchemaOptions_Email) isSchemaOptions_Contact() {}
+
+func (*SchemaOptions_Phone) isSchemaOptions_Contact() {}
+
+type isSchemaOptions_LegacyContact interface {
+	isSchemaOptions_LegacyContact()
+}
+
+type SchemaOptions_Fax struct {
+	Fax string `protobuf:"bytes,12,opt,name=fax,proto3,oneof"`
+}
+
+func (*SchemaOptions_Fax) isSchemaOptions_LegacyContact() {}
+
+// The request for an entry point.
+//
+// jsonschema:generate
+type EntryPoint_Request struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EntryPoint_Request) Reset() {
+	*x = EntryPoint_Request{}
+	mi := &file_buf_protoschema_test_v1_test_cases_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EntryPoint_Request) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EntryPoint_Request) ProtoMessage() {}
+
+func (x *EntryPoint_Request) ProtoReflect() protoreflect.Message {
+	mi := &file_buf_protoschema_test_v1_test_cases_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EntryPoint_Request.ProtoReflect.Descriptor instead.
+func (*EntryPoint_Request) Descriptor() ([]byte, []int) {
+	return file_buf_protoschema_test_v1_test_cases_proto_rawDescGZIP(), []int{3, 0}
+}
+
+func (x *EntryPoint_Request) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type EntryPoint_Response struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Request       *EntryPoint_Request    `protobuf:"bytes,1,opt,name=request,proto3" json:"request,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EntryPoint_Response) Reset() {
+	*x = EntryPoint_Response{}
+	mi := &file_buf_protoschema_test_v1_test_cases_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EntryPoint_Response) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EntryPoint_Response) ProtoMessage() {}
+
+func (x *EntryPoint_Response) ProtoReflect() protoreflect.Message {
+	mi := &file_buf_protoschema_test_v1_test_cases_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EntryPoint_Response.ProtoReflect.Descriptor instead.
+func (*EntryPoint_Response) Descriptor() ([]byte, []int) {
+	return file_buf_protoschema_test_v1_test_cases_proto_rawDescGZIP(), []int{3, 1}
+}
+
+func (x *EntryPoint_Response) GetRequest() *EntryPoint_Request {
+	if x != nil {
+		return x.Request
+	}
+	return nil
+}
+
 var File_buf_protoschema_test_v1_test_cases_proto protoreflect.FileDescriptor
 
 const file_buf_protoschema_test_v1_test_cases_proto_rawDesc = "" +
 	"\n" +
-	"(buf/protoschema/test/v1/test_cases.proto\x12\x17buf.protoschema.test.v1\x1a\x1bbuf/validate/validate.proto\x1a7bufext/cel/expr/conformance/proto3/test_all_types.proto\"x\n" +
+	"(buf/protoschema/test/v1/test_cases.proto\x12\x17buf.protoschema.test.v1\x1a buf/protoschema/v1/options.proto\x1a\x1bbuf/validate/validate.proto\x1a7bufext/cel/expr/conformance/proto3/test_all_types.proto\"x\n" +
 	"\x0fNestedReference\x12e\n" +
 	"\x0enested_message\x18\x01 \x01(\v2>.bufext.cel.expr.conformance.proto3.TestAllTypes.NestedMessageR\rnestedMessage\"\xf4\x01\n" +
 	"\rCustomOptions\x12O\n" +
@@ -264,7 +674,48 @@ const file_buf_protoschema_test_v1_test_cases_proto_rawDesc = "" +
 	"bool_field\x18\x03 \x01(\bR\tboolField\x12\x1f\n" +
 	"\vbytes_field\x18\x04 \x01(\fR\n" +
 	"bytesField\x12S\n" +
-	"\x10nested_reference\x18\x05 \x01(\v2(.buf.protoschema.test.v1.NestedReferenceR\x0fnestedReferenceB\x87\x02\n" +
+	"\x10nested_reference\x18\x05 \x01(\v2(.buf.protoschema.test.v1.NestedReferenceR\x0fnestedReference\"\x8f\x02\n" +
+	"\n" +
+	"EntryPoint\x12E\n" +
+	"\arequest\x18\x01 \x01(\v2+.buf.protoschema.test.v1.EntryPoint.RequestR\arequest\x12H\n" +
+	"\bresponse\x18\x02 \x01(\v2,.buf.protoschema.test.v1.EntryPoint.ResponseR\bresponse\x1a\x1d\n" +
+	"\aRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x1aQ\n" +
+	"\bResponse\x12E\n" +
+	"\arequest\x18\x01 \x01(\v2+.buf.protoschema.test.v1.EntryPoint.RequestR\arequest\"\xf0\x06\n" +
+	"\rSchemaOptions\x12\x1e\n" +
+	"\x04name\x18\x01 \x01(\tB\n" +
+	"\xbaJ\a\n" +
+	"\x05titleR\x04name\x125\n" +
+	"\tmax_count\x18\x02 \x01(\x05B\x18\xbaJ\x15\n" +
+	"\bmaxItems\n" +
+	"\tmax_itemsR\bmaxCount\x12g\n" +
+	"\x02id\x18\x03 \x01(\tBW\xbaJT\x1a\n" +
+	"Identifier\"\x16The unique identifier.*&\"8d9c6a52-8e34-4b4c-9a7c-4c1d5a2b3e6f\"2\x04uuid8\x01R\x02id\x12!\n" +
+	"\bpassword\x18\x04 \x01(\tB\x05\xbaJ\x02@\x01R\bpassword\x12(\n" +
+	"\vlegacy_name\x18\x05 \x01(\tB\a\xbaJ\x04\x10\x02H\x01R\n" +
+	"legacyName\x12*\n" +
+	"\rinternal_name\x18\x06 \x01(\tB\x05\xbaJ\x02\x10\x03R\finternalName\x12\x19\n" +
+	"\x04note\x18\a \x01(\tB\x05\xbaJ\x02\x10\x01R\x04note\x12E\n" +
+	"\x06status\x18\b \x01(\x0e2-.buf.protoschema.test.v1.SchemaOptions.StatusR\x06status\x12\x1e\n" +
+	"\x05links\x18\t \x03(\tB\b\xbaJ\x052\x03uriR\x05links\x12\"\n" +
+	"\x05email\x18\n" +
+	" \x01(\tB\n" +
+	"\xbaJ\a2\x05emailH\x00R\x05email\x12\x1d\n" +
+	"\x05phone\x18\v \x01(\tB\x05\xbaJ\x02\x10\x03H\x00R\x05phone\x12\x19\n" +
+	"\x03fax\x18\f \x01(\tB\x05\xbaJ\x02\x10\x03H\x01R\x03fax\x12&\n" +
+	"\bnickname\x18\r \x01(\tB\x05\xbaJ\x02\x10\x03H\x02R\bnickname\x88\x01\x01\x12\x1d\n" +
+	"\bold_name\x18\x0e \x01(\tB\x02\x18\x01R\aoldName\"\x83\x01\n" +
+	"\x06Status\x12\x16\n" +
+	"\x12STATUS_UNSPECIFIED\x10\x00\x12\x11\n" +
+	"\rSTATUS_ACTIVE\x10\x01\x12\x18\n" +
+	"\rSTATUS_LEGACY\x10\x02\x1a\x05\xbaJ\x02\b\x02\x12\x1b\n" +
+	"\x0eSTATUS_REMOVED\x10\x03\x1a\a\xbaJ\x04\b\x03\x10\x01\x12\x17\n" +
+	"\x0fSTATUS_OBSOLETE\x10\x04\x1a\x02\b\x01:O\xbaJL\n" +
+	"\tAnnotated\x12(A message annotated with schema options.\x1a\x13{\"name\": \"example\"} \x01B\t\n" +
+	"\acontactB\x10\n" +
+	"\x0elegacy_contactB\v\n" +
+	"\t_nicknameB\x87\x02\n" +
 	"\x1bcom.buf.protoschema.test.v1B\x0eTestCasesProtoP\x01ZYgithub.com/bufbuild/protoschema-plugins/internal/gen/proto/buf/protoschema/test/v1;testv1\xa2\x02\x03BPT\xaa\x02\x17Buf.Protoschema.Test.V1\xca\x02\x17Buf\\Protoschema\\Test\\V1\xe2\x02#Buf\\Protoschema\\Test\\V1\\GPBMetadata\xea\x02\x1aBuf::Protoschema::Test::V1b\x06proto3"
 
 var (
@@ -279,21 +730,31 @@ func file_buf_protoschema_test_v1_test_cases_proto_rawDescGZIP() []byte {
 	return file_buf_protoschema_test_v1_test_cases_proto_rawDescData
 }
 
-var file_buf_protoschema_test_v1_test_cases_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_buf_protoschema_test_v1_test_cases_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_buf_protoschema_test_v1_test_cases_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
 var file_buf_protoschema_test_v1_test_cases_proto_goTypes = []any{
-	(*NestedReference)(nil),                   // 0: buf.protoschema.test.v1.NestedReference
-	(*CustomOptions)(nil),                     // 1: buf.protoschema.test.v1.CustomOptions
-	(*IgnoreField)(nil),                       // 2: buf.protoschema.test.v1.IgnoreField
-	(*proto3.TestAllTypes_NestedMessage)(nil), // 3: bufext.cel.expr.conformance.proto3.TestAllTypes.NestedMessage
+	(SchemaOptions_Status)(0),                 // 0: buf.protoschema.test.v1.SchemaOptions.Status
+	(*NestedReference)(nil),                   // 1: buf.protoschema.test.v1.NestedReference
+	(*CustomOptions)(nil),                     // 2: buf.protoschema.test.v1.CustomOptions
+	(*IgnoreField)(nil),                       // 3: buf.protoschema.test.v1.IgnoreField
+	(*EntryPoint)(nil),                        // 4: buf.protoschema.test.v1.EntryPoint
+	(*SchemaOptions)(nil),                     // 5: buf.protoschema.test.v1.SchemaOptions
+	(*EntryPoint_Request)(nil),                // 6: buf.protoschema.test.v1.EntryPoint.Request
+	(*EntryPoint_Response)(nil),               // 7: buf.protoschema.test.v1.EntryPoint.Response
+	(*proto3.TestAllTypes_NestedMessage)(nil), // 8: bufext.cel.expr.conformance.proto3.TestAllTypes.NestedMessage
 }
 var file_buf_protoschema_test_v1_test_cases_proto_depIdxs = []int32{
-	3, // 0: buf.protoschema.test.v1.NestedReference.nested_message:type_name -> bufext.cel.expr.conformance.proto3.TestAllTypes.NestedMessage
-	0, // 1: buf.protoschema.test.v1.IgnoreField.nested_reference:type_name -> buf.protoschema.test.v1.NestedReference
-	2, // [2:2] is the sub-list for method output_type
-	2, // [2:2] is the sub-list for method input_type
-	2, // [2:2] is the sub-list for extension type_name
-	2, // [2:2] is the sub-list for extension extendee
-	0, // [0:2] is the sub-list for field type_name
+	8, // 0: buf.protoschema.test.v1.NestedReference.nested_message:type_name -> bufext.cel.expr.conformance.proto3.TestAllTypes.NestedMessage
+	1, // 1: buf.protoschema.test.v1.IgnoreField.nested_reference:type_name -> buf.protoschema.test.v1.NestedReference
+	6, // 2: buf.protoschema.test.v1.EntryPoint.request:type_name -> buf.protoschema.test.v1.EntryPoint.Request
+	7, // 3: buf.protoschema.test.v1.EntryPoint.response:type_name -> buf.protoschema.test.v1.EntryPoint.Response
+	0, // 4: buf.protoschema.test.v1.SchemaOptions.status:type_name -> buf.protoschema.test.v1.SchemaOptions.Status
+	6, // 5: buf.protoschema.test.v1.EntryPoint.Response.request:type_name -> buf.protoschema.test.v1.EntryPoint.Request
+	6, // [6:6] is the sub-list for method output_type
+	6, // [6:6] is the sub-list for method input_type
+	6, // [6:6] is the sub-list for extension type_name
+	6, // [6:6] is the sub-list for extension extendee
+	0, // [0:6] is the sub-list for field type_name
 }
 
 func init() { file_buf_protoschema_test_v1_test_cases_proto_init() }
@@ -304,18 +765,24 @@ func file_buf_protoschema_test_v1_test_cases_proto_init() {
 	file_buf_protoschema_test_v1_test_cases_proto_msgTypes[1].OneofWrappers = []any{
 		(*CustomOptions_StringField)(nil),
 	}
+	file_buf_protoschema_test_v1_test_cases_proto_msgTypes[4].OneofWrappers = []any{
+		(*SchemaOptions_Email)(nil),
+		(*SchemaOptions_Phone)(nil),
+		(*SchemaOptions_Fax)(nil),
+	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_buf_protoschema_test_v1_test_cases_proto_rawDesc), len(file_buf_protoschema_test_v1_test_cases_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   3,
+			NumEnums:      1,
+			NumMessages:   7,
 			NumExtensions: 0,
 			NumServices:   0,
 		},
 		GoTypes:           file_buf_protoschema_test_v1_test_cases_proto_goTypes,
 		DependencyIndexes: file_buf_protoschema_test_v1_test_cases_proto_depIdxs,
+		EnumInfos:         file_buf_protoschema_test_v1_test_cases_proto_enumTypes,
 		MessageInfos:      file_buf_protoschema_test_v1_test_cases_proto_msgTypes,
 	}.Build()
 	File_buf_protoschema_test_v1_test_cases_proto = out.File
@@ -0,0 +1,515 @@
+// Copyright 2024-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.8
+// 	protoc        (unknown)
+// source: buf/protoschema/v1/options.proto
+
+package protoschemav1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	descriptorpb "google.golang.org/protobuf/types/descriptorpb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// The visibility of a field or enum value in the generated schemas.
+type Visibility int32
+
+const (
+	// The visibility is determined by the `jsonschema:ignore` and `jsonschema:hide` comment
+	// directives, and is visible if neither is present.
+	Visibility_VISIBILITY_UNSPECIFIED Visibility = 0
+	// Listed in the generated schemas, ignoring any comment directives.
+	Visibility_VISIBILITY_VISIBLE Visibility = 1
+	// Accepted, but not listed, so editors do not suggest it.
+	//
+	// Equivalent to the `jsonschema:hide` comment directive.
+	Visibility_VISIBILITY_HIDDEN Visibility = 2
+	// Omitted from the generated JSON schemas, which do not accept ignored fields or enum value
+	// names.
+	//
+	// Equivalent to the `jsonschema:ignore` comment directive. Normalized messages (e.g. PubSub,
+	// Avro and BigQuery schemas) are not affected, as the fields may still be present on the wire.
+	Visibility_VISIBILITY_IGNORED Visibility = 3
+)
+
+// Enum value maps for Visibility.
+var (
+	Visibility_name = map[int32]string{
+		0: "VISIBILITY_UNSPECIFIED",
+		1: "VISIBILITY_VISIBLE",
+		2: "VISIBILITY_HIDDEN",
+		3: "VISIBILITY_IGNORED",
+	}
+	Visibility_value = map[string]int32{
+		"VISIBILITY_UNSPECIFIED": 0,
+		"VISIBILITY_VISIBLE":     1,
+		"VISIBILITY_HIDDEN":      2,
+		"VISIBILITY_IGNORED":     3,
+	}
+)
+
+func (x Visibility) Enum() *Visibility {
+	p := new(Visibility)
+	*p = x
+	return p
+}
+
+func (x Visibility) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Visibility) Descriptor() protoreflect.EnumDescriptor {
+	return file_buf_protoschema_v1_options_proto_enumTypes[0].Descriptor()
+}
+
+func (Visibility) Type() protoreflect.EnumType {
+	return &file_buf_protoschema_v1_options_proto_enumTypes[0]
+}
+
+func (x Visibility) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Visibility.Descriptor instead.
+func (Visibility) EnumDescriptor() ([]byte, []int) {
+	return file_buf_protoschema_v1_options_proto_rawDescGZIP(), []int{0}
+}
+
+// The options for the schemas generated for a message.
+type MessageSchemaOptions struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The title of the schema, instead of the first paragraph of the leading comments.
+	Title string `protobuf:"bytes,1,opt,name=title,proto3" json:"title,omitempty"`
+	// The description of the schema, instead of the leading comments.
+	Description string `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	// Example values of the message, in JSON.
+	Examples []string `protobuf:"bytes,3,rep,name=examples,proto3" json:"examples,omitempty"`
+	// Whether the message is deprecated.
+	//
+	// Marks the JSON schema as deprecated, and normalized messages with the deprecated option.
+	Deprecated    bool `protobuf:"varint,4,opt,name=deprecated,proto3" json:"deprecated,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MessageSchemaOptions) Reset() {
+	*x = MessageSchemaOptions{}
+	mi := &file_buf_protoschema_v1_options_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MessageSchemaOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MessageSchemaOptions) ProtoMessage() {}
+
+func (x *MessageSchemaOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_buf_protoschema_v1_options_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MessageSchemaOptions.ProtoReflect.Descriptor instead.
+func (*MessageSchemaOptions) Descriptor() ([]byte, []int) {
+	return file_buf_protoschema_v1_options_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *MessageSchemaOptions) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *MessageSchemaOptions) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *MessageSchemaOptions) GetExamples() []string {
+	if x != nil {
+		return x.Examples
+	}
+	return nil
+}
+
+func (x *MessageSchemaOptions) GetDeprecated() bool {
+	if x != nil {
+		return x.Deprecated
+	}
+	return false
+}
+
+// The options for the schemas generated for a field.
+type FieldSchemaOptions struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Additional names accepted for the field, e.g. the previous names of a renamed field.
+	//
+	// Aliases are accepted by JSON schemas in addition to the proto and JSON names of the field,
+	// except in strict mode, as they are not understood by ProtoJSON.
+	Aliases []string `protobuf:"bytes,1,rep,name=aliases,proto3" json:"aliases,omitempty"`
+	// The visibility of the field.
+	Visibility Visibility `protobuf:"varint,2,opt,name=visibility,proto3,enum=buf.protoschema.v1.Visibility" json:"visibility,omitempty"`
+	// The title of the schema, instead of the first paragraph of the leading comments.
+	Title string `protobuf:"bytes,3,opt,name=title,proto3" json:"title,omitempty"`
+	// The description of the schema, instead of the leading comments.
+	Description string `protobuf:"bytes,4,opt,name=description,proto3" json:"description,omitempty"`
+	// Example values of the field, in JSON.
+	Examples []string `protobuf:"bytes,5,rep,name=examples,proto3" json:"examples,omitempty"`
+	// The JSON schema format of the field values, e.g. "uuid" or "uri".
+	//
+	// For repeated and map fields, the format applies to each item or value.
+	Format string `protobuf:"bytes,6,opt,name=format,proto3" json:"format,omitempty"`
+	// Whether the field is only returned by the server, and ignored when sent to it.
+	ReadOnly bool `protobuf:"varint,7,opt,name=read_only,json=readOnly,proto3" json:"read_only,omitempty"`
+	// Whether the field is only sent to the server, and never returned by it.
+	WriteOnly bool `protobuf:"varint,8,opt,name=write_only,json=writeOnly,proto3" json:"write_only,omitempty"`
+	// Whether the field is deprecated.
+	//
+	// Marks the JSON schema as deprecated, and normalized fields with the deprecated option.
+	Deprecated    bool `protobuf:"varint,9,opt,name=deprecated,proto3" json:"deprecated,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FieldSchemaOptions) Reset() {
+	*x = FieldSchemaOptions{}
+	mi := &file_buf_protoschema_v1_options_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FieldSchemaOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FieldSchemaOptions) ProtoMessage() {}
+
+func (x *FieldSchemaOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_buf_protoschema_v1_options_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FieldSchemaOptions.ProtoReflect.Descriptor instead.
+func (*FieldSchemaOptions) Descriptor() ([]byte, []int) {
+	return file_buf_protoschema_v1_options_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *FieldSchemaOptions) GetAliases() []string {
+	if x != nil {
+		return x.Aliases
+	}
+	return nil
+}
+
+func (x *FieldSchemaOptions) GetVisibility() Visibility {
+	if x != nil {
+		return x.Visibility
+	}
+	return Visibility_VISIBILITY_UNSPECIFIED
+}
+
+func (x *FieldSchemaOptions) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *FieldSchemaOptions) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *FieldSchemaOptions) GetExamples() []string {
+	if x != nil {
+		return x.Examples
+	}
+	return nil
+}
+
+func (x *FieldSchemaOptions) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+func (x *FieldSchemaOptions) GetReadOnly() bool {
+	if x != nil {
+		return x.ReadOnly
+	}
+	return false
+}
+
+func (x *FieldSchemaOptions) GetWriteOnly() bool {
+	if x != nil {
+		return x.WriteOnly
+	}
+	return false
+}
+
+func (x *FieldSchemaOptions) GetDeprecated() bool {
+	if x != nil {
+		return x.Deprecated
+	}
+	return false
+}
+
+// The options for the schemas generated for an enum value.
+type EnumValueSchemaOptions struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The visibility of the enum value.
+	Visibility Visibility `protobuf:"varint,1,opt,name=visibility,proto3,enum=buf.protoschema.v1.Visibility" json:"visibility,omitempty"`
+	// Whether the enum value is deprecated.
+	//
+	// Like the built-in deprecated option, deprecated values are accepted, but not listed by JSON
+	// schemas. Normalized enum values are marked with the deprecated option.
+	Deprecated    bool `protobuf:"varint,2,opt,name=deprecated,proto3" json:"deprecated,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EnumValueSchemaOptions) Reset() {
+	*x = EnumValueSchemaOptions{}
+	mi := &file_buf_protoschema_v1_options_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EnumValueSchemaOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EnumValueSchemaOptions) ProtoMessage() {}
+
+func (x *EnumValueSchemaOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_buf_protoschema_v1_options_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EnumValueSchemaOptions.ProtoReflect.Descriptor instead.
+func (*EnumValueSchemaOptions) Descriptor() ([]byte, []int) {
+	return file_buf_protoschema_v1_options_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *EnumValueSchemaOptions) GetVisibility() Visibility {
+	if x != nil {
+		return x.Visibility
+	}
+	return Visibility_VISIBILITY_UNSPECIFIED
+}
+
+func (x *EnumValueSchemaOptions) GetDeprecated() bool {
+	if x != nil {
+		return x.Deprecated
+	}
+	return false
+}
+
+var file_buf_protoschema_v1_options_proto_extTypes = []protoimpl.ExtensionInfo{
+	{
+		ExtendedType:  (*descriptorpb.MessageOptions)(nil),
+		ExtensionType: (*MessageSchemaOptions)(nil),
+		Field:         1191,
+		Name:          "buf.protoschema.v1.message",
+		Tag:           "bytes,1191,opt,name=message",
+		Filename:      "buf/protoschema/v1/options.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.FieldOptions)(nil),
+		ExtensionType: (*FieldSchemaOptions)(nil),
+		Field:         1191,
+		Name:          "buf.protoschema.v1.field",
+		Tag:           "bytes,1191,opt,name=field",
+		Filename:      "buf/protoschema/v1/options.proto",
+	},
+	{
+		ExtendedType:  (*descriptorpb.EnumValueOptions)(nil),
+		ExtensionType: (*EnumValueSchemaOptions)(nil),
+		Field:         1191,
+		Name:          "buf.protoschema.v1.enum_value",
+		Tag:           "bytes,1191,opt,name=enum_value",
+		Filename:      "buf/protoschema/v1/options.proto",
+	},
+}
+
+// Extension fields to descriptorpb.MessageOptions.
+var (
+	// optional buf.protoschema.v1.MessageSchemaOptions message = 1191;
+	E_Message = &file_buf_protoschema_v1_options_proto_extTypes[0]
+)
+
+// Extension fields to descriptorpb.FieldOptions.
+var (
+	// optional buf.protoschema.v1.FieldSchemaOptions field = 1191;
+	E_Field = &file_buf_protoschema_v1_options_proto_extTypes[1]
+)
+
+// Extension fields to descriptorpb.EnumValueOptions.
+var (
+	// optional buf.protoschema.v1.EnumValueSchemaOptions enum_value = 1191;
+	E_EnumValue = &file_buf_protoschema_v1_options_proto_extTypes[2]
+)
+
+var File_buf_protoschema_v1_options_proto protoreflect.FileDescriptor
+
+const file_buf_protoschema_v1_options_proto_rawDesc = "" +
+	"\n" +
+	" buf/protoschema/v1/options.proto\x12\x12buf.protoschema.v1\x1a google/protobuf/descriptor.proto\"\x8a\x01\n" +
+	"\x14MessageSchemaOptions\x12\x14\n" +
+	"\x05title\x18\x01 \x01(\tR\x05title\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x12\x1a\n" +
+	"\bexamples\x18\x03 \x03(\tR\bexamples\x12\x1e\n" +
+	"\n" +
+	"deprecated\x18\x04 \x01(\bR\n" +
+	"deprecated\"\xb6\x02\n" +
+	"\x12FieldSchemaOptions\x12\x18\n" +
+	"\aaliases\x18\x01 \x03(\tR\aaliases\x12>\n" +
+	"\n" +
+	"visibility\x18\x02 \x01(\x0e2\x1e.buf.protoschema.v1.VisibilityR\n" +
+	"visibility\x12\x14\n" +
+	"\x05title\x18\x03 \x01(\tR\x05title\x12 \n" +
+	"\vdescription\x18\x04 \x01(\tR\vdescription\x12\x1a\n" +
+	"\bexamples\x18\x05 \x03(\tR\bexamples\x12\x16\n" +
+	"\x06format\x18\x06 \x01(\tR\x06format\x12\x1b\n" +
+	"\tread_only\x18\a \x01(\bR\breadOnly\x12\x1d\n" +
+	"\n" +
+	"write_only\x18\b \x01(\bR\twriteOnly\x12\x1e\n" +
+	"\n" +
+	"deprecated\x18\t \x01(\bR\n" +
+	"deprecated\"x\n" +
+	"\x16EnumValueSchemaOptions\x12>\n" +
+	"\n" +
+	"visibility\x18\x01 \x01(\x0e2\x1e.buf.protoschema.v1.VisibilityR\n" +
+	"visibility\x12\x1e\n" +
+	"\n" +
+	"deprecated\x18\x02 \x01(\bR\n" +
+	"deprecated*o\n" +
+	"\n" +
+	"Visibility\x12\x1a\n" +
+	"\x16VISIBILITY_UNSPECIFIED\x10\x00\x12\x16\n" +
+	"\x12VISIBILITY_VISIBLE\x10\x01\x12\x15\n" +
+	"\x11VISIBILITY_HIDDEN\x10\x02\x12\x16\n" +
+	"\x12VISIBILITY_IGNORED\x10\x03:d\n" +
+	"\amessage\x12\x1f.google.protobuf.MessageOptions\x18\xa7\t \x01(\v2(.buf.protoschema.v1.MessageSchemaOptionsR\amessage:\\\n" +
+	"\x05field\x12\x1d.google.protobuf.FieldOptions\x18\xa7\t \x01(\v2&.buf.protoschema.v1.FieldSchemaOptionsR\x05field:m\n" +
+	"\n" +
+	"enum_value\x12!.google.protobuf.EnumValueOptions\x18\xa7\t \x01(\v2*.buf.protoschema.v1.EnumValueSchemaOptionsR\tenumValueB\xed\x01\n" +
+	"\x16com.buf.protoschema.v1B\fOptionsProtoP\x01Z[github.com/bufbuild/protoschema-plugins/internal/gen/proto/buf/protoschema/v1;protoschemav1\xa2\x02\x03BPX\xaa\x02\x12Buf.Protoschema.V1\xca\x02\x12Buf\\Protoschema\\V1\xe2\x02\x1eBuf\\Protoschema\\V1\\GPBMetadata\xea\x02\x14Buf::Protoschema::V1b\x06proto3"
+
+var (
+	file_buf_protoschema_v1_options_proto_rawDescOnce sync.Once
+	file_buf_protoschema_v1_options_proto_rawDescData []byte
+)
+
+func file_buf_protoschema_v1_options_proto_rawDescGZIP() []byte {
+	file_buf_protoschema_v1_options_proto_rawDescOnce.Do(func() {
+		file_buf_protoschema_v1_options_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_buf_protoschema_v1_options_proto_rawDesc), len(file_buf_protoschema_v1_options_proto_rawDesc)))
+	})
+	return file_buf_protoschema_v1_options_proto_rawDescData
+}
+
+var file_buf_protoschema_v1_options_proto_enumTypes = make([]protoimpl.EnumInfo, 1)
+var file_buf_protoschema_v1_options_proto_msgTypes = make([]protoimpl.MessageInfo, 3)
+var file_buf_protoschema_v1_options_proto_goTypes = []any{
+	(Visibility)(0),                       // 0: buf.protoschema.v1.Visibility
+	(*MessageSchemaOptions)(nil),          // 1: buf.protoschema.v1.MessageSchemaOptions
+	(*FieldSchemaOptions)(nil),            // 2: buf.protoschema.v1.FieldSchemaOptions
+	(*EnumValueSchemaOptions)(nil),        // 3: buf.protoschema.v1.EnumValueSchemaOptions
+	(*descriptorpb.MessageOptions)(nil),   // 4: google.protobuf.MessageOptions
+	(*descriptorpb.FieldOptions)(nil),     // 5: google.protobuf.FieldOptions
+	(*descriptorpb.EnumValueOptions)(nil), // 6: google.protobuf.EnumValueOptions
+}
+var file_buf_protoschema_v1_options_proto_depIdxs = []int32{
+	0, // 0: buf.protoschema.v1.FieldSchemaOptions.visibility:type_name -> buf.protoschema.v1.Visibility
+	0, // 1: buf.protoschema.v1.EnumValueSchemaOptions.visibility:type_name -> buf.protoschema.v1.Visibility
+	4, // 2: buf.protoschema.v1.message:extendee -> google.protobuf.MessageOptions
+	5, // 3: buf.protoschema.v1.field:extendee -> google.protobuf.FieldOptions
+	6, // 4: buf.protoschema.v1.enum_value:extendee -> google.protobuf.EnumValueOptions
+	1, // 5: buf.protoschema.v1.message:type_name -> buf.protoschema.v1.MessageSchemaOptions
+	2, // 6: buf.protoschema.v1.field:type_name -> buf.protoschema.v1.FieldSchemaOptions
+	3, // 7: buf.protoschema.v1.enum_value:type_name -> buf.protoschema.v1.EnumValueSchemaOptions
+	8, // [8:8] is the sub-list for method output_type
+	8, // [8:8] is the sub-list for method input_type
+	5, // [5:8] is the sub-list for extension type_name
+	2, // [2:5] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_buf_protoschema_v1_options_proto_init() }
+func file_buf_protoschema_v1_options_proto_init() {
+	if File_buf_protoschema_v1_options_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_buf_protoschema_v1_options_proto_rawDesc), len(file_buf_protoschema_v1_options_proto_rawDesc)),
+			NumEnums:      1,
+			NumMessages:   3,
+			NumExtensions: 3,
+			NumServices:   0,
+		},
+		GoTypes:           file_buf_protoschema_v1_options_proto_goTypes,
+		DependencyIndexes: file_buf_protoschema_v1_options_proto_depIdxs,
+		EnumInfos:         file_buf_protoschema_v1_options_proto_enumTypes,
+		MessageInfos:      file_buf_protoschema_v1_options_proto_msgTypes,
+		ExtensionInfos:    file_buf_protoschema_v1_options_proto_extTypes,
+	}.Build()
+	File_buf_protoschema_v1_options_proto = out.File
+	file_buf_protoschema_v1_options_proto_goTypes = nil
+	file_buf_protoschema_v1_options_proto_depIdxs = nil
+}
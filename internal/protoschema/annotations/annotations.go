@@ -0,0 +1,140 @@
+// Copyright 2024-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package annotations reads the buf.protoschema.v1 options of messages, fields and enum values,
+// and the other options that affect the generated schemas.
+package annotations
+
+import (
+	"slices"
+	"strings"
+
+	protoschemav1 "github.com/bufbuild/protoschema-plugins/internal/gen/proto/buf/protoschema/v1"
+	apiannotations "google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+const (
+	// ignoreDirective is the comment directive equivalent to protoschemav1.Visibility_VISIBILITY_IGNORED.
+	ignoreDirective = "jsonschema:ignore"
+	// hideDirective is the comment directive equivalent to protoschemav1.Visibility_VISIBILITY_HIDDEN.
+	hideDirective = "jsonschema:hide"
+)
+
+// GetMessageOptions returns the (buf.protoschema.v1.message) options of the given message, or nil
+// if not set.
+func GetMessageOptions(desc protoreflect.MessageDescriptor) *protoschemav1.MessageSchemaOptions {
+	result, _ := getExtension(desc.Options(), protoschemav1.E_Message).(*protoschemav1.MessageSchemaOptions)
+	return result
+}
+
+// GetFieldOptions returns the (buf.protoschema.v1.field) options of the given field, or nil if
+// not set.
+func GetFieldOptions(field protoreflect.FieldDescriptor) *protoschemav1.FieldSchemaOptions {
+	result, _ := getExtension(field.Options(), protoschemav1.E_Field).(*protoschemav1.FieldSchemaOptions)
+	return result
+}
+
+// GetEnumValueOptions returns the (buf.protoschema.v1.enum_value) options of the given enum value,
+// or nil if not set.
+func GetEnumValueOptions(value protoreflect.EnumValueDescriptor) *protoschemav1.EnumValueSchemaOptions {
+	result, _ := getExtension(value.Options(), protoschemav1.E_EnumValue).(*protoschemav1.EnumValueSchemaOptions)
+	return result
+}
+
+// GetFieldVisibility returns the visibility of the given field.
+//
+// If not set in the field options, the visibility is determined by the comment directives in the
+// leading or trailing comments of the field.
+func GetFieldVisibility(field protoreflect.FieldDescriptor) protoschemav1.Visibility {
+	if visibility := GetFieldOptions(field).GetVisibility(); visibility != protoschemav1.Visibility_VISIBILITY_UNSPECIFIED {
+		return visibility
+	}
+	srcLoc := field.ParentFile().SourceLocations().ByDescriptor(field)
+	switch {
+	case hasDirective(srcLoc.LeadingComments, ignoreDirective),
+		hasDirective(srcLoc.TrailingComments, ignoreDirective):
+		return protoschemav1.Visibility_VISIBILITY_IGNORED
+	case hasDirective(srcLoc.LeadingComments, hideDirective),
+		hasDirective(srcLoc.TrailingComments, hideDirective):
+		return protoschemav1.Visibility_VISIBILITY_HIDDEN
+	default:
+		return protoschemav1.Visibility_VISIBILITY_VISIBLE
+	}
+}
+
+// IsDeprecated returns true if the given message, field or enum value is deprecated, either by the
+// built-in deprecated option or its buf.protoschema.v1 options.
+func IsDeprecated(desc protoreflect.Descriptor) bool {
+	switch desc := desc.(type) {
+	case protoreflect.MessageDescriptor:
+		options, _ := desc.Options().(*descriptorpb.MessageOptions)
+		return options.GetDeprecated() || GetMessageOptions(desc).GetDeprecated()
+	case protoreflect.FieldDescriptor:
+		options, _ := desc.Options().(*descriptorpb.FieldOptions)
+		return options.GetDeprecated() || GetFieldOptions(desc).GetDeprecated()
+	case protoreflect.EnumValueDescriptor:
+		options, _ := desc.Options().(*descriptorpb.EnumValueOptions)
+		return options.GetDeprecated() || GetEnumValueOptions(desc).GetDeprecated()
+	default:
+		return false
+	}
+}
+
+// HasFieldBehavior returns true if the google.api.field_behavior option of the given field
+// contains the given behavior.
+func HasFieldBehavior(field protoreflect.FieldDescriptor, behavior apiannotations.FieldBehavior) bool {
+	behaviors, _ := getExtension(field.Options(), apiannotations.E_FieldBehavior).([]apiannotations.FieldBehavior)
+	return slices.Contains(behaviors, behavior)
+}
+
+// GetHTTPRule returns the google.api.http rule of the given method, or nil if not set.
+func GetHTTPRule(method protoreflect.MethodDescriptor) *apiannotations.HttpRule {
+	result, _ := getExtension(method.Options(), apiannotations.E_Http).(*apiannotations.HttpRule)
+	return result
+}
+
+// hasDirective returns true if the given comments contain the directive as a whole word, so
+// that e.g. "no-jsonschema:ignore" or a sentence quoting "`jsonschema:ignore`" do not match.
+func hasDirective(comments string, directive string) bool {
+	return slices.Contains(strings.Fields(comments), directive)
+}
+
+// getExtension returns the value of the given extension in the given options, or nil if not set.
+func getExtension(options proto.Message, extType protoreflect.ExtensionType) any {
+	if options == nil || !options.ProtoReflect().IsValid() {
+		return nil
+	}
+	// Round-trip the options, as the extension is an unknown field when the descriptors were
+	// built without the extension linked in, or a dynamic extension when built from source.
+	data, err := proto.Marshal(options)
+	if err != nil {
+		return nil
+	}
+	msgType, err := protoregistry.GlobalTypes.FindMessageByName(extType.TypeDescriptor().ContainingMessage().FullName())
+	if err != nil {
+		return nil
+	}
+	resolved := msgType.New().Interface()
+	if err := proto.Unmarshal(data, resolved); err != nil {
+		return nil
+	}
+	if !proto.HasExtension(resolved, extType) {
+		return nil
+	}
+	return proto.GetExtension(resolved, extType)
+}
@@ -0,0 +1,72 @@
+// Copyright 2024-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package annotations
+
+import (
+	"testing"
+
+	"github.com/bufbuild/protocompile"
+	protoschemav1 "github.com/bufbuild/protoschema-plugins/internal/gen/proto/buf/protoschema/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+func TestGetFieldVisibility(t *testing.T) {
+	t.Parallel()
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{
+			Accessor: protocompile.SourceAccessorFromMap(map[string]string{
+				"shop/v1/order.proto": `
+					syntax = "proto3";
+					package shop.v1;
+					message Order {
+					  // jsonschema:ignore
+					  string ignored = 1;
+					  string trailing = 2; // jsonschema:hide
+					  // The secret.
+					  //
+					  // jsonschema:hide
+					  string hidden = 3;
+					  // Unlike the note, this field is not marked jsonschema:ignored.
+					  string longer_word = 4;
+					  // Use "jsonschema:ignore" to omit a field from the schema.
+					  string quoted = 5;
+					  // no-jsonschema:hide
+					  string prefixed = 6;
+					  string visible = 7;
+					}
+				`,
+			}),
+		}),
+		SourceInfoMode: protocompile.SourceInfoStandard,
+	}
+	files, err := compiler.Compile(t.Context(), "shop/v1/order.proto")
+	require.NoError(t, err)
+	fields := files[0].Messages().Get(0).Fields()
+	tests := map[protoreflect.Name]protoschemav1.Visibility{
+		"ignored":     protoschemav1.Visibility_VISIBILITY_IGNORED,
+		"trailing":    protoschemav1.Visibility_VISIBILITY_HIDDEN,
+		"hidden":      protoschemav1.Visibility_VISIBILITY_HIDDEN,
+		"longer_word": protoschemav1.Visibility_VISIBILITY_VISIBLE,
+		"quoted":      protoschemav1.Visibility_VISIBILITY_VISIBLE,
+		"prefixed":    protoschemav1.Visibility_VISIBILITY_VISIBLE,
+		"visible":     protoschemav1.Visibility_VISIBILITY_VISIBLE,
+	}
+	for name, want := range tests {
+		field := fields.ByName(name)
+		require.NotNil(t, field, name)
+		require.Equal(t, want, GetFieldVisibility(field), name)
+	}
+}
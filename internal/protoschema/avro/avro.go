@@ -0,0 +1,367 @@
+// Copyright 2024-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package avro generates Avro schemas for protobuf messages.
+//
+// The generator walks the message descriptors directly: the default schema represents oneofs,
+// enums and well-known types with native Avro types, which the messages returned by
+// normalize.Normalizer no longer distinguish. The PubSub Avro schema is instead generated for the
+// normalized message with WithNormalized, so it describes the PubSub protobuf schema.
+package avro
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const (
+	// FileExtension is the file extension for the Avro schema files.
+	FileExtension = "avsc"
+)
+
+// An enumeration of the Avro type names.
+const (
+	avroArray   = "array"
+	avroBoolean = "boolean"
+	avroBytes   = "bytes"
+	avroDouble  = "double"
+	avroEnum    = "enum"
+	avroFloat   = "float"
+	avroInt     = "int"
+	avroLong    = "long"
+	avroMap     = "map"
+	avroNull    = "null"
+	avroRecord  = "record"
+	avroString  = "string"
+)
+
+// GeneratorOption is an option for Generate.
+type GeneratorOption func(*generator)
+
+// WithNormalized generates the schema of a message normalized for the PubSub protobuf schema, so
+// the Avro records have the same fields as the normalized messages.
+//
+// Specifically:
+//   - Well-known types are records of their fields, instead of logical or primitive types.
+//   - Open enums are ints, as Avro enums cannot hold unknown values (and open enums are
+//     normalized to int32 fields in proto2).
+//   - Each field of a oneof is a separate nullable field, instead of a single union field.
+func WithNormalized() GeneratorOption {
+	return func(g *generator) {
+		g.normalized = true
+	}
+}
+
+// Generate generates an Avro schema in the form of a single self-contained record for the given
+// message descriptor.
+//
+// Like the normalized PubSub schema, all referenced types are inlined into the schema. Each
+// message and enum is defined once, named by its fully-qualified protobuf name, and referenced
+// by name afterward, which also allows for recursive types.
+func Generate(input protoreflect.MessageDescriptor, opts ...GeneratorOption) (string, error) {
+	generator := &generator{
+		named: make(map[protoreflect.FullName]struct{}),
+	}
+	for _, opt := range opts {
+		opt(generator)
+	}
+	schema, err := generator.generateRecord(input)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data) + "\n", nil
+}
+
+type generator struct {
+	// named is the set of named types that have already been defined in the schema.
+	named      map[protoreflect.FullName]struct{}
+	normalized bool
+}
+
+// define records the definition of the named type, returning false if it was already defined.
+func (g *generator) define(name protoreflect.FullName) bool {
+	if _, ok := g.named[name]; ok {
+		return false
+	}
+	g.named[name] = struct{}{}
+	return true
+}
+
+func (g *generator) generateRecord(desc protoreflect.MessageDescriptor) (any, error) {
+	if !g.define(desc.FullName()) {
+		return string(desc.FullName()), nil // Already defined.
+	}
+	schema := map[string]any{
+		"type": avroRecord,
+		"name": string(desc.FullName()),
+	}
+	setDoc(desc, schema)
+	fields := make([]map[string]any, 0, desc.Fields().Len())
+	for i := range desc.Fields().Len() {
+		field := desc.Fields().Get(i)
+		if oneof := field.ContainingOneof(); oneof != nil && !oneof.IsSynthetic() && !g.normalized {
+			// The oneof is generated as a single field, in place of its first field.
+			if oneof.Fields().Get(0) != field {
+				continue
+			}
+			fieldSchema, err := g.generateOneof(oneof)
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, fieldSchema)
+			continue
+		}
+		fieldSchema, err := g.generateField(field)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, fieldSchema)
+	}
+	schema["fields"] = fields
+	return schema, nil
+}
+
+func (g *generator) generateField(field protoreflect.FieldDescriptor) (map[string]any, error) {
+	schema := map[string]any{
+		"name": string(field.Name()),
+	}
+	setDoc(field, schema)
+	switch {
+	case field.IsMap():
+		// Avro map keys are always strings, like in the JSON encoding of protobuf.
+		valueType, err := g.generateType(field.MapValue())
+		if err != nil {
+			return nil, err
+		}
+		schema["type"] = map[string]any{"type": avroMap, "values": valueType}
+		schema["default"] = map[string]any{}
+	case field.IsList():
+		itemType, err := g.generateType(field)
+		if err != nil {
+			return nil, err
+		}
+		schema["type"] = map[string]any{"type": avroArray, "items": itemType}
+		schema["default"] = []any{}
+	case field.HasPresence():
+		valueType, err := g.generateType(field)
+		if err != nil {
+			return nil, err
+		}
+		schema["type"] = []any{avroNull, valueType}
+		schema["default"] = nil
+	default:
+		valueType, err := g.generateType(field)
+		if err != nil {
+			return nil, err
+		}
+		schema["type"] = valueType
+		if value, ok := g.getDefault(field); ok {
+			schema["default"] = value
+		}
+	}
+	return schema, nil
+}
+
+// generateOneof generates a nullable union field for the given oneof.
+//
+// Avro unions cannot contain more than one unnamed type of the same kind, so any field with a
+// type already in the union is wrapped in a record named after the field.
+func (g *generator) generateOneof(oneof protoreflect.OneofDescriptor) (map[string]any, error) {
+	schema := map[string]any{
+		"name":    string(oneof.Name()),
+		"default": nil,
+	}
+	setDoc(oneof, schema)
+	union := []any{avroNull}
+	seen := map[string]struct{}{avroNull: {}}
+	for i := range oneof.Fields().Len() {
+		field := oneof.Fields().Get(i)
+		valueType, err := g.generateType(field)
+		if err != nil {
+			return nil, err
+		}
+		key := getUnionKey(valueType)
+		if _, ok := seen[key]; ok {
+			if !g.define(field.FullName()) {
+				return nil, fmt.Errorf("duplicate name %q", field.FullName())
+			}
+			valueType = map[string]any{
+				"type": avroRecord,
+				"name": string(field.FullName()),
+				"fields": []map[string]any{
+					{"name": string(field.Name()), "type": valueType},
+				},
+			}
+			key = string(field.FullName())
+		}
+		seen[key] = struct{}{}
+		union = append(union, valueType)
+	}
+	schema["type"] = union
+	return schema, nil
+}
+
+// generateType generates the type of a single value of the given field.
+func (g *generator) generateType(field protoreflect.FieldDescriptor) (any, error) {
+	switch field.Kind() {
+	case protoreflect.BoolKind:
+		return avroBoolean, nil
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		return avroInt, nil
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		// Unsigned 32-bit integers do not fit in an Avro int. Avro has no unsigned 64-bit
+		// integer, so values above the maximum long cannot be represented.
+		return avroLong, nil
+	case protoreflect.FloatKind:
+		return avroFloat, nil
+	case protoreflect.DoubleKind:
+		return avroDouble, nil
+	case protoreflect.StringKind:
+		return avroString, nil
+	case protoreflect.BytesKind:
+		return avroBytes, nil
+	case protoreflect.EnumKind:
+		if g.normalized && !field.Enum().IsClosed() {
+			return avroInt, nil
+		}
+		return g.generateEnum(field.Enum()), nil
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return g.generateMessage(field.Message())
+	default:
+		return nil, fmt.Errorf("unsupported field kind %v", field.Kind())
+	}
+}
+
+func (g *generator) generateEnum(desc protoreflect.EnumDescriptor) any {
+	if !g.define(desc.FullName()) {
+		return string(desc.FullName()) // Already defined.
+	}
+	symbols := make([]string, desc.Values().Len())
+	for i := range desc.Values().Len() {
+		symbols[i] = string(desc.Values().Get(i).Name())
+	}
+	schema := map[string]any{
+		"type":    avroEnum,
+		"name":    string(desc.FullName()),
+		"symbols": symbols,
+		// Unknown values are read as the default value.
+		"default": symbols[0],
+	}
+	setDoc(desc, schema)
+	return schema
+}
+
+// generateMessage generates the type of a message, using logical and primitive types for the
+// well-known types with a special JSON representation.
+//
+// Durations are records of their fields, as the Avro duration logical type is a number of
+// months, days and milliseconds, which cannot represent negative durations or nanoseconds.
+func (g *generator) generateMessage(desc protoreflect.MessageDescriptor) (any, error) {
+	if g.normalized {
+		return g.generateRecord(desc)
+	}
+	switch desc.FullName() {
+	case "google.protobuf.Timestamp":
+		return map[string]any{"type": avroLong, "logicalType": "timestamp-micros"}, nil
+	case "google.protobuf.BoolValue",
+		"google.protobuf.BytesValue",
+		"google.protobuf.DoubleValue",
+		"google.protobuf.FloatValue",
+		"google.protobuf.Int32Value",
+		"google.protobuf.Int64Value",
+		"google.protobuf.StringValue",
+		"google.protobuf.UInt32Value",
+		"google.protobuf.UInt64Value":
+		// Wrappers are represented by their value, and are nullable as fields with presence.
+		return g.generateType(desc.Fields().ByName("value"))
+	default:
+		return g.generateRecord(desc)
+	}
+}
+
+// getDefault returns the Avro default value of a field without presence.
+func (g *generator) getDefault(field protoreflect.FieldDescriptor) (any, bool) {
+	switch field.Kind() {
+	case protoreflect.BoolKind:
+		return field.Default().Bool(), true
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return field.Default().Int(), true
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return field.Default().Uint(), true
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		value := field.Default().Float()
+		if math.IsInf(value, 0) || math.IsNaN(value) {
+			return nil, false // Not representable in JSON.
+		}
+		return value, true
+	case protoreflect.StringKind:
+		return field.Default().String(), true
+	case protoreflect.BytesKind:
+		// Avro bytes defaults are strings of the code points 0-255.
+		value := field.Default().Bytes()
+		runes := make([]rune, len(value))
+		for i, b := range value {
+			runes[i] = rune(b)
+		}
+		return string(runes), true
+	case protoreflect.EnumKind:
+		if g.normalized && !field.Enum().IsClosed() {
+			return int32(field.Default().Enum()), true
+		}
+		if value := field.DefaultEnumValue(); value != nil {
+			return string(value.Name()), true
+		}
+		return string(field.Enum().Values().Get(0).Name()), true
+	default:
+		return nil, false
+	}
+}
+
+// getUnionKey returns the key that identifies the type within a union.
+//
+// Avro unions may only contain one schema of each type, except for named types with different
+// names.
+func getUnionKey(schema any) string {
+	switch schema := schema.(type) {
+	case string:
+		return schema
+	case map[string]any:
+		if name, ok := schema["name"].(string); ok {
+			return name
+		}
+		if typeName, ok := schema["type"].(string); ok {
+			return typeName
+		}
+	}
+	return ""
+}
+
+func setDoc(desc protoreflect.Descriptor, schema map[string]any) {
+	src := desc.ParentFile().SourceLocations().ByDescriptor(desc)
+	if comments := strings.TrimSpace(src.LeadingComments); comments != "" {
+		schema["doc"] = comments
+	}
+}
@@ -0,0 +1,247 @@
+// Copyright 2024-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquery
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"buf.build/gen/go/bufbuild/protovalidate/protocolbuffers/go/buf/validate"
+	"buf.build/go/protovalidate"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const (
+	// FileExtension is the file extension for the BigQuery table schema files.
+	FileExtension = "bigquery.json"
+	// DefaultMaxDepth is the default maximum recursion depth of messages.
+	DefaultMaxDepth = 1
+	// maxNesting is the maximum depth of nested records supported by BigQuery.
+	maxNesting = 15
+	// maxDescriptionLength is the maximum length of a column description in BigQuery.
+	maxDescriptionLength = 1024
+)
+
+// An enumeration of the BigQuery column types.
+const (
+	bqBoolean   = "BOOLEAN"
+	bqBytes     = "BYTES"
+	bqFloat     = "FLOAT"
+	bqInteger   = "INTEGER"
+	bqJSON      = "JSON"
+	bqNumeric   = "NUMERIC"
+	bqRecord    = "RECORD"
+	bqString    = "STRING"
+	bqTimestamp = "TIMESTAMP"
+)
+
+// An enumeration of the BigQuery column modes.
+const (
+	bqNullable = "NULLABLE"
+	bqRepeated = "REPEATED"
+	bqRequired = "REQUIRED"
+)
+
+// GeneratorOption is an option for Generate.
+type GeneratorOption func(*generator)
+
+// WithMaxDepth sets the maximum recursion depth of messages, defaulting to DefaultMaxDepth.
+//
+// BigQuery does not support recursive schemas, so a message field is omitted when its message
+// is already nested within itself the maximum number of times. With a depth of 1, a message is
+// never nested within itself.
+func WithMaxDepth(maxDepth int) GeneratorOption {
+	return func(g *generator) {
+		g.maxDepth = maxDepth
+	}
+}
+
+// Generate generates a BigQuery table schema, in the JSON form accepted by the bq tool, for the
+// given message descriptor.
+//
+// The columns match the fields of the message, as normalized by the PubSub schema, so the table
+// can be written to by a PubSub BigQuery subscription.
+func Generate(input protoreflect.MessageDescriptor, opts ...GeneratorOption) (string, error) {
+	generator := &generator{
+		maxDepth: DefaultMaxDepth,
+		parents:  make(map[protoreflect.FullName]int),
+	}
+	for _, opt := range opts {
+		opt(generator)
+	}
+	schema, err := generator.generateFields(input, 1)
+	if err != nil {
+		return "", err
+	}
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data) + "\n", nil
+}
+
+type generator struct {
+	maxDepth int
+	// parents counts the messages containing the message being generated, by name.
+	parents map[protoreflect.FullName]int
+}
+
+// generateFields generates the columns for the fields of the given message, at the given depth.
+//
+// No columns are generated past the maximum nesting of BigQuery, or past the maximum recursion
+// depth of the message.
+func (g *generator) generateFields(desc protoreflect.MessageDescriptor, depth int) ([]map[string]any, error) {
+	fields := make([]map[string]any, 0, desc.Fields().Len())
+	if depth > maxNesting || g.parents[desc.FullName()] >= g.maxDepth {
+		return fields, nil
+	}
+	g.parents[desc.FullName()]++
+	defer func() { g.parents[desc.FullName()]-- }()
+	for i := range desc.Fields().Len() {
+		field := desc.Fields().Get(i)
+		fieldSchema, err := g.generateField(field, depth)
+		if err != nil {
+			return nil, err
+		}
+		if fieldSchema != nil {
+			fields = append(fields, fieldSchema)
+		}
+	}
+	return fields, nil
+}
+
+// generateField generates the column for the given field, or nil if the field has no columns
+// within the maximum nesting or recursion depth.
+func (g *generator) generateField(field protoreflect.FieldDescriptor, depth int) (map[string]any, error) {
+	schema := map[string]any{
+		"name": string(field.Name()),
+	}
+	setDescription(field, schema)
+	switch {
+	case field.IsMap():
+		// Maps are repeated key/value records, like the map entries of the PubSub schema.
+		if depth+1 > maxNesting {
+			return nil, nil
+		}
+		key, err := g.generateField(field.MapKey(), depth+1)
+		if err != nil {
+			return nil, err
+		}
+		value, err := g.generateField(field.MapValue(), depth+1)
+		if err != nil {
+			return nil, err
+		}
+		fields := []map[string]any{key}
+		if value != nil {
+			fields = append(fields, value)
+		}
+		schema["type"] = bqRecord
+		schema["mode"] = bqRepeated
+		schema["fields"] = fields
+		return schema, nil
+	case field.IsList():
+		schema["mode"] = bqRepeated
+	default:
+		rules, err := protovalidate.ResolveFieldRules(field)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve rules for %q: %w", field.FullName(), err)
+		}
+		if rules.GetRequired() && rules.GetIgnore() != validate.Ignore_IGNORE_IF_ZERO_VALUE {
+			schema["mode"] = bqRequired
+		} else {
+			schema["mode"] = bqNullable
+		}
+	}
+	if err := g.generateType(field, depth, schema); err != nil {
+		return nil, err
+	}
+	if fields, ok := schema["fields"].([]map[string]any); ok && len(fields) == 0 {
+		// BigQuery records must have at least one column.
+		return nil, nil
+	}
+	return schema, nil
+}
+
+// generateType sets the type of the column for the given field.
+func (g *generator) generateType(field protoreflect.FieldDescriptor, depth int, schema map[string]any) error {
+	switch field.Kind() {
+	case protoreflect.BoolKind:
+		schema["type"] = bqBoolean
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Uint32Kind, protoreflect.Fixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		schema["type"] = bqInteger
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		// Unsigned 64-bit integers do not fit in a BigQuery integer.
+		schema["type"] = bqNumeric
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		schema["type"] = bqFloat
+	case protoreflect.StringKind:
+		schema["type"] = bqString
+	case protoreflect.BytesKind:
+		schema["type"] = bqBytes
+	case protoreflect.EnumKind:
+		// Enums are written as their numeric value.
+		schema["type"] = bqInteger
+	case protoreflect.MessageKind, protoreflect.GroupKind:
+		return g.generateMessage(field.Message(), depth, schema)
+	default:
+		return fmt.Errorf("unsupported field kind %v", field.Kind())
+	}
+	return nil
+}
+
+// generateMessage sets the type of the column for a message field, using BigQuery types for the
+// well-known types with a matching type.
+func (g *generator) generateMessage(desc protoreflect.MessageDescriptor, depth int, schema map[string]any) error {
+	switch desc.FullName() {
+	case "google.protobuf.Timestamp":
+		schema["type"] = bqTimestamp
+	case "google.protobuf.Struct", "google.protobuf.Value", "google.protobuf.ListValue":
+		schema["type"] = bqJSON
+	case "google.protobuf.BoolValue",
+		"google.protobuf.BytesValue",
+		"google.protobuf.DoubleValue",
+		"google.protobuf.FloatValue",
+		"google.protobuf.Int32Value",
+		"google.protobuf.Int64Value",
+		"google.protobuf.StringValue",
+		"google.protobuf.UInt32Value",
+		"google.protobuf.UInt64Value":
+		// Wrappers are represented by their value, which is nullable like any singular column.
+		return g.generateType(desc.Fields().ByName("value"), depth, schema)
+	default:
+		fields, err := g.generateFields(desc, depth+1)
+		if err != nil {
+			return err
+		}
+		schema["type"] = bqRecord
+		schema["fields"] = fields
+	}
+	return nil
+}
+
+func setDescription(desc protoreflect.Descriptor, schema map[string]any) {
+	src := desc.ParentFile().SourceLocations().ByDescriptor(desc)
+	comments := strings.TrimSpace(src.LeadingComments)
+	if comments == "" {
+		return
+	}
+	if runes := []rune(comments); len(runes) > maxDescriptionLength {
+		comments = string(runes[:maxDescriptionLength])
+	}
+	schema["description"] = comments
+}
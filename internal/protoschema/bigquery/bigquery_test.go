@@ -0,0 +1,131 @@
+// Copyright 2024-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package bigquery
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/bufbuild/protoschema-plugins/internal/gen/proto/bufext/cel/expr/conformance/proto3"
+	"github.com/bufbuild/protoschema-plugins/internal/protoschema/golden"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBigQueryGolden(t *testing.T) {
+	t.Parallel()
+	dirPath := filepath.FromSlash("../../testdata/bigquery")
+	testDescs, err := golden.GetTestDescriptors("../../testdata")
+	require.NoError(t, err)
+	for _, testDesc := range testDescs {
+		filePath := filepath.Join(dirPath, string(testDesc.FullName()))
+		data, err := Generate(testDesc)
+		require.NoError(t, err)
+		err = golden.CheckGolden(fmt.Sprintf("%s.%s", filePath, FileExtension), data)
+		require.NoError(t, err)
+	}
+}
+
+func TestBigQueryTypes(t *testing.T) {
+	t.Parallel()
+	columns := generateColumns(t, 1)
+	tests := []struct {
+		name string
+		want column
+	}{
+		{name: "single_int32", want: column{Type: "INTEGER", Mode: "NULLABLE"}},
+		{name: "single_uint64", want: column{Type: "NUMERIC", Mode: "NULLABLE"}},
+		{name: "single_double", want: column{Type: "FLOAT", Mode: "NULLABLE"}},
+		{name: "single_bytes", want: column{Type: "BYTES", Mode: "NULLABLE"}},
+		{name: "single_nested_enum", want: column{Type: "INTEGER", Mode: "NULLABLE"}},
+		{name: "single_timestamp", want: column{Type: "TIMESTAMP", Mode: "NULLABLE"}},
+		{name: "single_struct", want: column{Type: "JSON", Mode: "NULLABLE"}},
+		{name: "single_int64_wrapper", want: column{Type: "INTEGER", Mode: "NULLABLE"}},
+		{name: "repeated_string", want: column{Type: "STRING", Mode: "REPEATED"}},
+		{name: "repeated_timestamp", want: column{Type: "TIMESTAMP", Mode: "REPEATED"}},
+		{
+			name: "single_nested_message",
+			want: column{Type: "RECORD", Mode: "NULLABLE", Fields: []column{
+				{Name: "bb", Type: "INTEGER", Mode: "NULLABLE"},
+			}},
+		},
+		{
+			name: "map_string_string",
+			want: column{Type: "RECORD", Mode: "REPEATED", Fields: []column{
+				{Name: "key", Type: "STRING", Mode: "NULLABLE"},
+				{Name: "value", Type: "STRING", Mode: "NULLABLE"},
+			}},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			got, ok := columns["payload"].field(test.name)
+			require.True(t, ok)
+			test.want.Name = test.name
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
+
+func TestBigQueryMaxDepth(t *testing.T) {
+	t.Parallel()
+	for _, maxDepth := range []int{1, 2, 5} {
+		columns := generateColumns(t, maxDepth)
+		// The child column is nested until the message is nested within itself maxDepth times.
+		depth := 1
+		child, ok := columns["child"]
+		for ok {
+			depth++
+			child, ok = child.field("child")
+		}
+		assert.Equal(t, maxDepth, depth)
+	}
+}
+
+// column is a column of a BigQuery table schema.
+type column struct {
+	Name        string   `json:"name"`
+	Type        string   `json:"type"`
+	Mode        string   `json:"mode"`
+	Description string   `json:"description,omitempty"`
+	Fields      []column `json:"fields,omitempty"`
+}
+
+func (c column) field(name string) (column, bool) {
+	for _, field := range c.Fields {
+		if field.Name == name {
+			return field, true
+		}
+	}
+	return column{}, false
+}
+
+// generateColumns generates the schema of the recursive NestedTestAllTypes message, keyed by
+// column name.
+func generateColumns(t *testing.T, maxDepth int) map[string]column {
+	t.Helper()
+	data, err := Generate((&proto3.NestedTestAllTypes{}).ProtoReflect().Descriptor(), WithMaxDepth(maxDepth))
+	require.NoError(t, err)
+	var columns []column
+	require.NoError(t, json.Unmarshal([]byte(data), &columns))
+	result := make(map[string]column, len(columns))
+	for _, column := range columns {
+		result[column.Name] = column
+	}
+	return result
+}
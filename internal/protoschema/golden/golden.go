@@ -50,9 +50,14 @@ func GetTestDescriptors(testdataPath string) ([]protoreflect.MessageDescriptor,
 		"buf.protoschema.test.v1.NestedReference",
 		"buf.protoschema.test.v1.CustomOptions",
 		"buf.protoschema.test.v1.IgnoreField",
+		"buf.protoschema.test.v1.EntryPoint",
 		"buf.protoschema.test.v1.ConstraintTest",
 		"buf.protoschema.test.v1.ConstraintTests",
 		"buf.protoschema.test.v1.Product",
+		"buf.protoschema.test.v1.SchemaOptions",
+		"buf.protoschema.v1.MessageSchemaOptions",
+		"buf.protoschema.v1.FieldSchemaOptions",
+		"buf.protoschema.v1.EnumValueSchemaOptions",
 	}
 
 	msgs := make([]protoreflect.MessageDescriptor, len(fqns))
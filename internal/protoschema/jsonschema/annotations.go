@@ -0,0 +1,122 @@
+// Copyright 2024-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/bufbuild/protoschema-plugins/internal/protoschema/annotations"
+	apiannotations "google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// schemaAnnotations are the options shared by the (buf.protoschema.v1.message) and
+// (buf.protoschema.v1.field) options.
+type schemaAnnotations interface {
+	GetTitle() string
+	GetDescription() string
+	GetExamples() []string
+}
+
+// setMessageAnnotations applies the (buf.protoschema.v1.message) and deprecated options of the
+// given message to its schema.
+func (p *Generator) setMessageAnnotations(desc protoreflect.MessageDescriptor, schema map[string]any) error {
+	if err := p.setAnnotations(annotations.GetMessageOptions(desc), schema); err != nil {
+		return err
+	}
+	if annotations.IsDeprecated(desc) {
+		schema["deprecated"] = true
+	}
+	return nil
+}
+
+// setFieldAnnotations applies the (buf.protoschema.v1.field), google.api.field_behavior and
+// deprecated options of the given field to its schema.
+func (p *Generator) setFieldAnnotations(field protoreflect.FieldDescriptor, schema map[string]any) error {
+	options := annotations.GetFieldOptions(field)
+	if err := p.setAnnotations(options, schema); err != nil {
+		return err
+	}
+	if options.GetFormat() != "" {
+		// The format applies to each value of a repeated or map field.
+		valueSchema := schema
+		switch {
+		case field.IsList():
+			valueSchema, _ = schema["items"].(map[string]any)
+		case field.IsMap():
+			valueSchema, _ = schema["additionalProperties"].(map[string]any)
+		}
+		if valueSchema != nil {
+			valueSchema["format"] = options.GetFormat()
+		}
+	}
+	if isReadOnly(field) {
+		schema["readOnly"] = true
+	}
+	if isWriteOnly(field) {
+		schema["writeOnly"] = true
+	}
+	if annotations.IsDeprecated(field) {
+		schema["deprecated"] = true
+	}
+	return nil
+}
+
+// isVariantExcluded returns true if the given field is dropped from the variant of the schemas,
+// e.g. an output only field from the input variant.
+func (p *Generator) isVariantExcluded(field protoreflect.FieldDescriptor) bool {
+	switch p.variant {
+	case variantInput:
+		return isReadOnly(field)
+	case variantOutput:
+		return isWriteOnly(field)
+	default:
+		return false
+	}
+}
+
+// isReadOnly returns true if the given field is output only.
+func isReadOnly(field protoreflect.FieldDescriptor) bool {
+	return annotations.GetFieldOptions(field).GetReadOnly() ||
+		annotations.HasFieldBehavior(field, apiannotations.FieldBehavior_OUTPUT_ONLY)
+}
+
+// isWriteOnly returns true if the given field is input only.
+func isWriteOnly(field protoreflect.FieldDescriptor) bool {
+	return annotations.GetFieldOptions(field).GetWriteOnly() ||
+		annotations.HasFieldBehavior(field, apiannotations.FieldBehavior_INPUT_ONLY)
+}
+
+// setAnnotations applies the given options to the given schema, overriding the title and
+// description taken from the comments.
+func (p *Generator) setAnnotations(options schemaAnnotations, schema map[string]any) error {
+	if options.GetTitle() != "" {
+		schema["title"] = options.GetTitle()
+	}
+	if options.GetDescription() != "" {
+		schema["description"] = options.GetDescription()
+	}
+	if len(options.GetExamples()) > 0 {
+		examples := make([]any, len(options.GetExamples()))
+		for i, example := range options.GetExamples() {
+			if err := json.Unmarshal([]byte(example), &examples[i]); err != nil {
+				return fmt.Errorf("failed to parse example %q: %w", example, err)
+			}
+		}
+		schema["examples"] = examples
+	}
+	return nil
+}
@@ -0,0 +1,215 @@
+// Copyright 2024-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonschema
+
+import (
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"buf.build/gen/go/bufbuild/protovalidate/protocolbuffers/go/buf/validate"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const (
+	anyFullName      = "google.protobuf.Any"
+	anyTypeURLPrefix = "type.googleapis.com/"
+)
+
+// anyPayload is the schema of a message embedded in a google.protobuf.Any.
+//
+// The schema references the properties of the message's own schema, so it is only
+// populated once all schemas have been generated.
+type anyPayload struct {
+	desc   protoreflect.MessageDescriptor
+	schema map[string]any
+}
+
+// generateAnyValidation generates a schema for a google.protobuf.Any field that only allows
+// the types listed by its rules, or the types given by WithAnyTypes.
+//
+// The @type property is restricted to the allowed type URLs and, for each type with a known
+// descriptor, an if/then branch applies the schema of that type.
+func (p *Generator) generateAnyValidation(entry *msgSchema, field protoreflect.FieldDescriptor, rules *validate.FieldRules, schema map[string]any) error {
+	typeSchema := map[string]any{"type": jsString}
+	schema["type"] = jsObject
+	schema["properties"] = map[string]any{"@type": typeSchema}
+
+	typeURLs := rules.GetAny().GetIn()
+	if len(typeURLs) == 0 {
+		for _, desc := range p.anyTypes {
+			typeURLs = append(typeURLs, anyTypeURLPrefix+string(desc.FullName()))
+		}
+	}
+	notIn := rules.GetAny().GetNotIn()
+	if len(typeURLs) == 0 {
+		if len(notIn) > 0 {
+			typeSchema["not"] = map[string]any{"enum": notIn}
+		}
+		return nil
+	}
+	typeURLs = slices.DeleteFunc(slices.Clone(typeURLs), func(typeURL string) bool {
+		return slices.Contains(notIn, typeURL)
+	})
+	typeSchema["enum"] = typeURLs
+	schema["required"] = []string{"@type"}
+
+	var allOf []map[string]any
+	for _, typeURL := range typeURLs {
+		desc := p.findAnyType(field.ParentFile(), typeURL)
+		if desc == nil {
+			continue // Unknown type, so only the type URL is validated.
+		}
+		thenSchema, err := p.generateAnyPayload(entry, desc)
+		if err != nil {
+			return err
+		}
+		allOf = append(allOf, map[string]any{
+			"if": map[string]any{
+				"properties": map[string]any{"@type": map[string]any{"const": typeURL}},
+				"required":   []string{"@type"},
+			},
+			"then": thenSchema,
+		})
+	}
+	appendAllOf(schema, allOf...)
+	return nil
+}
+
+// generateAnyPayload returns the schema for the fields of an Any containing the given type.
+func (p *Generator) generateAnyPayload(entry *msgSchema, desc protoreflect.MessageDescriptor) (map[string]any, error) {
+	entry.addRef(desc.FullName())
+	if _, err := p.generate(desc); err != nil {
+		return nil, err
+	}
+	if _, ok := p.custom[desc.FullName()]; ok {
+		// Well-known types with a custom JSON representation are wrapped in a value property.
+		return map[string]any{
+			"properties": map[string]any{
+				"@type": map[string]any{},
+				"value": map[string]any{"$ref": p.getID(desc, false)},
+			},
+			"required":             []string{"value"},
+			"additionalProperties": false,
+		}, nil
+	}
+	// The fields of the message are inlined next to @type.
+	payload := anyPayload{desc: desc, schema: make(map[string]any)}
+	p.anyPayloads = append(p.anyPayloads, payload)
+	return payload.schema, nil
+}
+
+// generateAnyPayloadReferences populates the schema of an Any payload with references to the
+// parts of the message's schema, adding @type as an allowed property.
+func (p *Generator) generateAnyPayloadReferences(payload anyPayload) {
+	entry := p.schema[payload.desc.FullName()]
+	ref := func(tokens ...string) map[string]any {
+		return map[string]any{"$ref": getPointer(p.getID(payload.desc, false), tokens...)}
+	}
+	payload.schema["type"] = jsObject
+	properties := map[string]any{"@type": map[string]any{}}
+	if entryProperties, ok := entry.schema["properties"].(map[string]any); ok {
+		for name := range entryProperties {
+			properties[name] = ref("properties", name)
+		}
+	}
+	payload.schema["properties"] = properties
+	if entryPatternProperties, ok := entry.schema["patternProperties"].(map[string]any); ok {
+		patternProperties := make(map[string]any, len(entryPatternProperties))
+		for pattern := range entryPatternProperties {
+			patternProperties[pattern] = ref("patternProperties", pattern)
+		}
+		payload.schema["patternProperties"] = patternProperties
+	}
+	if required, ok := entry.schema["required"]; ok {
+		payload.schema["required"] = required
+	}
+	if additionalProperties, ok := entry.schema["additionalProperties"]; ok {
+		payload.schema["additionalProperties"] = additionalProperties
+	}
+	if entryAllOf, ok := entry.schema["allOf"].([]map[string]any); ok {
+		allOf := make([]map[string]any, len(entryAllOf))
+		for i := range entryAllOf {
+			allOf[i] = ref("allOf", strconv.Itoa(i))
+		}
+		payload.schema["allOf"] = allOf
+	}
+}
+
+// findAnyType returns the descriptor of the message with the given type URL, searching the
+// types given by WithAnyTypes and the given file and its (transitive) imports.
+func (p *Generator) findAnyType(file protoreflect.FileDescriptor, typeURL string) protoreflect.MessageDescriptor {
+	name := protoreflect.FullName(typeURL[strings.LastIndex(typeURL, "/")+1:])
+	for _, desc := range p.anyTypes {
+		if desc.FullName() == name {
+			return desc
+		}
+	}
+	return findMessage(file, name, make(map[string]struct{}))
+}
+
+// findMessage returns the descriptor of the message with the given name in the given file or
+// its imports, or nil if not found.
+func findMessage(file protoreflect.FileDescriptor, name protoreflect.FullName, seen map[string]struct{}) protoreflect.MessageDescriptor {
+	if _, ok := seen[file.Path()]; ok {
+		return nil
+	}
+	seen[file.Path()] = struct{}{}
+	relative := string(name)
+	if file.Package() != "" {
+		var ok bool
+		if relative, ok = strings.CutPrefix(relative, string(file.Package())+"."); !ok {
+			relative = ""
+		}
+	}
+	if relative != "" {
+		// Walk the (possibly nested) message names.
+		messages := file.Messages()
+		var desc protoreflect.MessageDescriptor
+		for part := range strings.SplitSeq(relative, ".") {
+			if desc = messages.ByName(protoreflect.Name(part)); desc == nil {
+				break
+			}
+			messages = desc.Messages()
+		}
+		if desc != nil {
+			return desc
+		}
+	}
+	for i := range file.Imports().Len() {
+		if desc := findMessage(file.Imports().Get(i).FileDescriptor, name, seen); desc != nil {
+			return desc
+		}
+	}
+	return nil
+}
+
+// getPointer returns a reference to the part of the schema with the given ID at the given
+// JSON pointer tokens.
+func getPointer(id string, tokens ...string) string {
+	var result strings.Builder
+	result.WriteString(id)
+	if !strings.Contains(id, "#") {
+		result.WriteString("#")
+	}
+	for _, token := range tokens {
+		token = strings.ReplaceAll(token, "~", "~0")
+		token = strings.ReplaceAll(token, "/", "~1")
+		result.WriteString("/")
+		result.WriteString(url.PathEscape(token))
+	}
+	return result.String()
+}
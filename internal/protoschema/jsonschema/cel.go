@@ -0,0 +1,534 @@
+// Copyright 2024-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonschema
+
+import (
+	"buf.build/gen/go/bufbuild/protovalidate/protocolbuffers/go/buf/validate"
+	"github.com/google/cel-go/common"
+	"github.com/google/cel-go/common/ast"
+	"github.com/google/cel-go/common/operators"
+	"github.com/google/cel-go/common/overloads"
+	"github.com/google/cel-go/parser"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// celRule is a CEL rule attached to a field or message.
+type celRule struct {
+	id         string
+	expression string
+	message    string
+}
+
+// getCELRules returns the CEL rules in the given rules and expressions.
+func getCELRules(rules []*validate.Rule, expressions []string) []celRule {
+	result := make([]celRule, 0, len(rules)+len(expressions))
+	for _, rule := range rules {
+		result = append(result, celRule{
+			id:         rule.GetId(),
+			expression: rule.GetExpression(),
+			message:    rule.GetMessage(),
+		})
+	}
+	for _, expression := range expressions {
+		result = append(result, celRule{
+			id:         expression,
+			expression: expression,
+		})
+	}
+	return result
+}
+
+// celValue is a value a CEL expression refers to.
+type celValue struct {
+	field protoreflect.FieldDescriptor
+	// isList is true if the value is the repeated field itself, rather than an element.
+	isList bool
+	// names are the property names of the value in the enclosing message, or nil if the
+	// value is the instance being validated.
+	names []string
+}
+
+// wrap returns a schema applying the given schema to the value.
+func (v *celValue) wrap(schema map[string]any) map[string]any {
+	if v.names == nil {
+		return schema
+	}
+	properties := make(map[string]any, len(v.names))
+	for _, name := range v.names {
+		properties[name] = schema
+	}
+	return map[string]any{"properties": properties}
+}
+
+// celTranslator translates a subset of CEL expressions into equivalent JSON schema keywords.
+//
+// The supported subset is:
+//   - Comparisons (<, <=, >, >=, ==, !=) between a value and a constant.
+//   - size() bounds on strings, lists and maps.
+//   - matches() against a constant pattern.
+//   - in (and !in) a list of constants.
+//   - has() on message fields, and implications written as `!has(this.a) || ...`.
+//   - Conjunctions (&&) of the above, and conditionals returning an empty string on success.
+//
+// Translations never reject a value the expression accepts, but may accept values the
+// expression rejects (e.g. numbers encoded as strings, or RE2 vs ECMA 262 patterns).
+type celTranslator struct {
+	gen *Generator
+	// resolve returns the value the expression refers to, or nil if not supported.
+	resolve func(expr ast.Expr) *celValue
+	// has returns the field (and its property names) tested by a has() expression, or nil if
+	// not supported.
+	has func(expr ast.Expr) (protoreflect.FieldDescriptor, []string)
+}
+
+// generateCELValidation translates the given rules, returning the translated schemas and the
+// x-cel annotations for the rules that could not be translated.
+func (t *celTranslator) generateCELValidation(rules []celRule) ([]map[string]any, []map[string]any) {
+	var allOf, annotations []map[string]any
+	for _, rule := range rules {
+		var translated map[string]any
+		if tree, errs := parser.Parse(common.NewTextSource(rule.expression)); len(errs.GetErrors()) == 0 {
+			translated = t.translate(tree.Expr())
+		}
+		if translated != nil {
+			// Flatten conjunctions.
+			if conjunction, ok := translated["allOf"].([]map[string]any); ok && len(translated) == 1 {
+				allOf = append(allOf, conjunction...)
+			} else {
+				allOf = append(allOf, translated)
+			}
+			continue
+		}
+		annotation := map[string]any{
+			"id":         rule.id,
+			"expression": rule.expression,
+		}
+		if rule.message != "" {
+			annotation["message"] = rule.message
+		}
+		annotations = append(annotations, annotation)
+	}
+	return allOf, annotations
+}
+
+// translate returns a schema equivalent to the given boolean (or string message) expression,
+// or nil if the expression is not supported.
+func (t *celTranslator) translate(expr ast.Expr) map[string]any {
+	switch expr.Kind() {
+	case ast.SelectKind:
+		// A partial update need not set any field, so has() is only translated as a condition.
+		if expr.AsSelect().IsTestOnly() && t.gen.variant != variantPatch {
+			return t.translateHas(expr)
+		}
+		return nil
+	case ast.CallKind:
+	default:
+		return nil
+	}
+	call := expr.AsCall()
+	args := call.Args()
+	switch call.FunctionName() {
+	case operators.LogicalAnd:
+		var allOf []map[string]any
+		for _, arg := range args {
+			schema := t.translate(arg)
+			if schema == nil {
+				return nil
+			}
+			if conjunction, ok := schema["allOf"].([]map[string]any); ok && len(schema) == 1 {
+				allOf = append(allOf, conjunction...)
+			} else {
+				allOf = append(allOf, schema)
+			}
+		}
+		return map[string]any{"allOf": allOf}
+	case operators.LogicalOr:
+		// Only implications are supported.
+		if schema := t.translateImplication(args[0], args[1]); schema != nil {
+			return schema
+		}
+		return t.translateImplication(args[1], args[0])
+	case operators.LogicalNot:
+		return t.translateNot(args[0])
+	case operators.Conditional:
+		// A rule expression may return an empty string on success and an error message otherwise.
+		switch {
+		case isStringLiteral(args[1], true) && isStringLiteral(args[2], false):
+			return t.translate(args[0])
+		case isStringLiteral(args[1], false) && isStringLiteral(args[2], true):
+			return t.translateNot(args[0])
+		}
+		return nil
+	case operators.Less, operators.LessEquals, operators.Greater, operators.GreaterEquals,
+		operators.Equals, operators.NotEquals:
+		if schema := t.translateComparison(call.FunctionName(), args[0], args[1]); schema != nil {
+			return schema
+		}
+		return t.translateComparison(flipOperator(call.FunctionName()), args[1], args[0])
+	case operators.In:
+		return t.translateIn(args[0], args[1], false)
+	case overloads.Matches:
+		target, pattern := matchesArgs(call)
+		if target == nil {
+			return nil
+		}
+		value := t.resolve(target)
+		literal, ok := getLiteral(pattern).(string)
+		if value == nil || !ok || value.isList || value.field.IsMap() || value.field.Kind() != protoreflect.StringKind {
+			return nil
+		}
+		return value.wrap(map[string]any{"pattern": literal})
+	}
+	return nil
+}
+
+// translateNot returns a schema for the negation of the expression, or nil if not supported.
+//
+// Only negations that cannot reject a value accepted by the expression are supported.
+func (t *celTranslator) translateNot(expr ast.Expr) map[string]any {
+	if expr.Kind() == ast.SelectKind && expr.AsSelect().IsTestOnly() {
+		if schema := t.translateHas(expr); schema != nil {
+			return map[string]any{"not": schema}
+		}
+		return nil
+	}
+	if expr.Kind() == ast.CallKind && expr.AsCall().FunctionName() == operators.In {
+		args := expr.AsCall().Args()
+		return t.translateIn(args[0], args[1], true)
+	}
+	return nil
+}
+
+// translateHas returns a schema for a has() test on a message field.
+func (t *celTranslator) translateHas(expr ast.Expr) map[string]any {
+	if t.has == nil {
+		return nil
+	}
+	field, names := t.has(expr)
+	if field == nil {
+		return nil
+	}
+	options := t.gen.generateSetOptions(field, names)
+	switch len(options) {
+	case 0:
+		return nil
+	case 1:
+		return options[0]
+	default:
+		return map[string]any{"anyOf": options}
+	}
+}
+
+// translateImplication returns a schema for `!has(this.a) || consequent`, or nil if the
+// expression is not of that form.
+//
+// In the patch variant, consequents requiring a field are not translated, so neither
+// dependentRequired nor a `then` schema requiring a field is generated.
+func (t *celTranslator) translateImplication(negated ast.Expr, consequent ast.Expr) map[string]any {
+	if negated.Kind() != ast.CallKind || negated.AsCall().FunctionName() != operators.LogicalNot {
+		return nil
+	}
+	antecedent := negated.AsCall().Args()[0]
+	if antecedent.Kind() != ast.SelectKind || !antecedent.AsSelect().IsTestOnly() || t.has == nil {
+		return nil
+	}
+	ifSchema := t.translateHas(antecedent)
+	thenSchema := t.translate(consequent)
+	if ifSchema == nil || thenSchema == nil {
+		return nil
+	}
+	// Use dependentRequired when both sides are a single property with presence.
+	if consequent.Kind() == ast.SelectKind && consequent.AsSelect().IsTestOnly() {
+		ifField, ifNames := t.has(antecedent)
+		thenField, thenNames := t.has(consequent)
+		if len(ifNames) == 1 && len(thenNames) == 1 &&
+			t.gen.generateNotZeroValidation(ifField) == nil &&
+			t.gen.generateNotZeroValidation(thenField) == nil {
+			return map[string]any{"dependentRequired": map[string][]string{ifNames[0]: thenNames}}
+		}
+	}
+	return map[string]any{"if": ifSchema, "then": thenSchema}
+}
+
+// translateComparison returns a schema for `left op right`, where left is a value (or its
+// size) and right is a constant.
+func (t *celTranslator) translateComparison(op string, left ast.Expr, right ast.Expr) map[string]any {
+	literal := getLiteral(right)
+	if literal == nil {
+		return nil
+	}
+	if target := sizeArg(left); target != nil {
+		size, ok := literal.(int64)
+		value := t.resolve(target)
+		if !ok || value == nil {
+			return nil
+		}
+		return t.translateSize(op, value, size)
+	}
+	value := t.resolve(left)
+	if value == nil || value.isList || value.field.IsMap() {
+		return nil
+	}
+	switch value.field.Kind() {
+	case protoreflect.BoolKind, protoreflect.StringKind:
+		if !isKind(value.field.Kind(), literal) {
+			return nil
+		}
+		switch op {
+		case operators.Equals:
+			return value.wrap(map[string]any{"enum": []any{literal}})
+		case operators.NotEquals:
+			return value.wrap(map[string]any{"not": map[string]any{"enum": []any{literal}}})
+		}
+		return nil
+	case protoreflect.EnumKind, protoreflect.BytesKind, protoreflect.MessageKind, protoreflect.GroupKind:
+		return nil
+	}
+	if !isKind(value.field.Kind(), literal) {
+		return nil
+	}
+	var schema map[string]any
+	switch op {
+	case operators.Less:
+		schema = map[string]any{"exclusiveMaximum": literal}
+	case operators.LessEquals:
+		schema = map[string]any{"maximum": literal}
+	case operators.Greater:
+		schema = map[string]any{"exclusiveMinimum": literal}
+	case operators.GreaterEquals:
+		schema = map[string]any{"minimum": literal}
+	case operators.Equals:
+		schema = t.generateNumberEnum([]any{literal})
+	case operators.NotEquals:
+		schema = map[string]any{"not": map[string]any{"enum": []any{literal}}}
+	}
+	return value.wrap(schema)
+}
+
+// translateSize returns a schema for `size(value) op size`.
+func (t *celTranslator) translateSize(op string, value *celValue, size int64) map[string]any {
+	var minKey, maxKey string
+	switch {
+	case value.isList:
+		minKey, maxKey = "minItems", "maxItems"
+	case value.field.IsMap():
+		minKey, maxKey = "minProperties", "maxProperties"
+	case value.field.Kind() == protoreflect.StringKind:
+		// CEL and JSON schema both count code points.
+		minKey, maxKey = "minLength", "maxLength"
+	default:
+		return nil
+	}
+	schema := make(map[string]any)
+	switch op {
+	case operators.Less:
+		if size < 1 {
+			return nil
+		}
+		schema[maxKey] = size - 1
+	case operators.LessEquals:
+		schema[maxKey] = size
+	case operators.Greater:
+		schema[minKey] = size + 1
+	case operators.GreaterEquals:
+		schema[minKey] = size
+	case operators.Equals:
+		schema[minKey] = size
+		schema[maxKey] = size
+	default:
+		return nil
+	}
+	return value.wrap(schema)
+}
+
+// translateIn returns a schema for `target in [constants...]`, or its negation.
+func (t *celTranslator) translateIn(target ast.Expr, list ast.Expr, negate bool) map[string]any {
+	value := t.resolve(target)
+	if value == nil || value.isList || value.field.IsMap() || list.Kind() != ast.ListKind {
+		return nil
+	}
+	switch value.field.Kind() {
+	case protoreflect.EnumKind, protoreflect.BytesKind, protoreflect.MessageKind, protoreflect.GroupKind:
+		return nil
+	}
+	values := make([]any, 0, list.AsList().Size())
+	for _, elem := range list.AsList().Elements() {
+		literal := getLiteral(elem)
+		if literal == nil || !isKind(value.field.Kind(), literal) {
+			return nil
+		}
+		values = append(values, literal)
+	}
+	if negate {
+		return value.wrap(map[string]any{"not": map[string]any{"enum": values}})
+	}
+	switch value.field.Kind() {
+	case protoreflect.BoolKind, protoreflect.StringKind:
+		return value.wrap(map[string]any{"enum": values})
+	default:
+		return value.wrap(t.generateNumberEnum(values))
+	}
+}
+
+// generateNumberEnum returns a schema restricting a number to the given values.
+func (t *celTranslator) generateNumberEnum(values []any) map[string]any {
+	if t.gen.strict {
+		return map[string]any{"enum": values}
+	}
+	// Numbers may also be represented as strings.
+	return map[string]any{"anyOf": []map[string]any{
+		{"enum": values},
+		{"type": jsString},
+	}}
+}
+
+// newFieldCELTranslator returns a translator for rules where `this` is the field value.
+//
+// If isList is true, `this` is the repeated field itself, otherwise it is a single value.
+func (p *Generator) newFieldCELTranslator(field protoreflect.FieldDescriptor, isList bool) *celTranslator {
+	return &celTranslator{
+		gen: p,
+		resolve: func(expr ast.Expr) *celValue {
+			if expr.Kind() != ast.IdentKind || expr.AsIdent() != "this" {
+				return nil
+			}
+			return &celValue{field: field, isList: isList}
+		},
+	}
+}
+
+// newMessageCELTranslator returns a translator for rules where `this` is the message.
+func (p *Generator) newMessageCELTranslator(desc protoreflect.MessageDescriptor, fieldNames map[protoreflect.Name][]string) *celTranslator {
+	getField := func(expr ast.Expr) (protoreflect.FieldDescriptor, []string) {
+		if expr.Kind() != ast.SelectKind {
+			return nil, nil
+		}
+		operand := expr.AsSelect().Operand()
+		if operand.Kind() != ast.IdentKind || operand.AsIdent() != "this" {
+			return nil, nil
+		}
+		field := desc.Fields().ByName(protoreflect.Name(expr.AsSelect().FieldName()))
+		if field == nil || len(fieldNames[field.Name()]) == 0 {
+			return nil, nil
+		}
+		return field, fieldNames[field.Name()]
+	}
+	return &celTranslator{
+		gen: p,
+		resolve: func(expr ast.Expr) *celValue {
+			if expr.Kind() != ast.SelectKind || expr.AsSelect().IsTestOnly() {
+				return nil
+			}
+			field, names := getField(expr)
+			if field == nil {
+				return nil
+			}
+			return &celValue{field: field, isList: field.IsList(), names: names}
+		},
+		has: getField,
+	}
+}
+
+// generateFieldCELValidation translates the CEL rules of a field into the schema.
+func (p *Generator) generateFieldCELValidation(field protoreflect.FieldDescriptor, isList bool, rules *validate.FieldRules, schema map[string]any) {
+	celRules := getCELRules(rules.GetCel(), rules.GetCelExpression())
+	if len(celRules) == 0 {
+		return
+	}
+	allOf, annotations := p.newFieldCELTranslator(field, isList).generateCELValidation(celRules)
+	appendAllOf(schema, allOf...)
+	if len(annotations) > 0 {
+		schema["x-cel"] = annotations
+	}
+}
+
+// sizeArg returns the argument of a size() call, or nil if the expression is not a size() call.
+func sizeArg(expr ast.Expr) ast.Expr {
+	if expr.Kind() != ast.CallKind || expr.AsCall().FunctionName() != overloads.Size {
+		return nil
+	}
+	call := expr.AsCall()
+	switch {
+	case call.IsMemberFunction() && len(call.Args()) == 0:
+		return call.Target()
+	case !call.IsMemberFunction() && len(call.Args()) == 1:
+		return call.Args()[0]
+	}
+	return nil
+}
+
+// matchesArgs returns the target and pattern of a matches() call.
+func matchesArgs(call ast.CallExpr) (ast.Expr, ast.Expr) {
+	switch {
+	case call.IsMemberFunction() && len(call.Args()) == 1:
+		return call.Target(), call.Args()[0]
+	case !call.IsMemberFunction() && len(call.Args()) == 2:
+		return call.Args()[0], call.Args()[1]
+	}
+	return nil, nil
+}
+
+// getLiteral returns the Go value of a constant expression, or nil if the expression is not a
+// supported constant.
+func getLiteral(expr ast.Expr) any {
+	if expr.Kind() != ast.LiteralKind {
+		return nil
+	}
+	switch value := expr.AsLiteral().Value().(type) {
+	case bool, int64, uint64, float64, string:
+		return value
+	}
+	return nil
+}
+
+// isStringLiteral returns true if the expression is a string constant that is empty (or
+// non-empty if empty is false).
+func isStringLiteral(expr ast.Expr, empty bool) bool {
+	value, ok := getLiteral(expr).(string)
+	return ok && (value == "") == empty
+}
+
+// isKind returns true if the constant can be compared to a value of the given kind.
+func isKind(kind protoreflect.Kind, literal any) bool {
+	switch literal.(type) {
+	case bool:
+		return kind == protoreflect.BoolKind
+	case string:
+		return kind == protoreflect.StringKind
+	case int64, uint64, float64:
+		switch kind {
+		case protoreflect.BoolKind, protoreflect.StringKind, protoreflect.BytesKind, protoreflect.EnumKind,
+			protoreflect.MessageKind, protoreflect.GroupKind:
+			return false
+		}
+		return true
+	}
+	return false
+}
+
+// flipOperator returns the operator with its operands swapped.
+func flipOperator(op string) string {
+	switch op {
+	case operators.Less:
+		return operators.Greater
+	case operators.LessEquals:
+		return operators.GreaterEquals
+	case operators.Greater:
+		return operators.Less
+	case operators.GreaterEquals:
+		return operators.LessEquals
+	}
+	return op
+}
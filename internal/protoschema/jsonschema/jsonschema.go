@@ -15,16 +15,25 @@
 package jsonschema
 
 import (
+	"encoding/json"
 	"fmt"
 	"maps"
 	"math"
+	"regexp"
 	"slices"
 	"strings"
 	"unicode"
 
 	"buf.build/gen/go/bufbuild/protovalidate/protocolbuffers/go/buf/validate"
 	"buf.build/go/protovalidate"
+	protoschemav1 "github.com/bufbuild/protoschema-plugins/internal/gen/proto/buf/protoschema/v1"
+	"github.com/bufbuild/protoschema-plugins/internal/protoschema/annotations"
+	apiannotations "google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 // An enumeration of the JSON Schema type names.
@@ -102,14 +111,93 @@ func WithBundle() GeneratorOption {
 	}
 }
 
+// WithAnyTypes sets the message types allowed in a google.protobuf.Any field.
+//
+// The types are used for Any fields without an (buf.validate.field).any.in rule. They are also
+// used to resolve the types listed in any.in rules.
+func WithAnyTypes(descs ...protoreflect.MessageDescriptor) GeneratorOption {
+	return func(p *Generator) {
+		p.anyTypes = append(p.anyTypes, descs...)
+	}
+}
+
+// customGenerator generates the schema of a message with a custom JSON representation.
+//
+// The entry is the schema that will contain the generated schema, and rules are the field
+// rules when the schema is generated for a specific field, or nil otherwise.
+type customGenerator func(entry *msgSchema, desc protoreflect.MessageDescriptor, rules *validate.FieldRules, schema map[string]any) error
+
+// WithEnumSchemas sets the generator to generate a standalone schema for each enum, which
+// fields reference instead of inlining the enum values.
+func WithEnumSchemas() GeneratorOption {
+	return func(p *Generator) {
+		p.enumSchemas = true
+	}
+}
+
+// variant is a variant of the generated schemas, for a specific use of the messages.
+type variant string
+
+const (
+	// variantDefault is the schema of the message in any use.
+	variantDefault variant = ""
+	// variantInput is the schema of the message sent by clients, e.g. in a request.
+	variantInput variant = "input"
+	// variantOutput is the schema of the message returned by servers, e.g. in a response.
+	variantOutput variant = "output"
+	// variantPatch is the schema of a partial update of the message, e.g. with a field mask.
+	variantPatch variant = "patch"
+)
+
+// WithInput sets the generator to generate the input variant of the schemas, for messages sent
+// by clients.
+//
+// Output only fields, marked with the OUTPUT_ONLY google.api.field_behavior or read_only
+// (buf.protoschema.v1.field) option, are dropped, and so are not allowed unless additional
+// properties are. The IDs of the schemas are suffixed with ".input".
+func WithInput() GeneratorOption {
+	return func(p *Generator) {
+		p.variant = variantInput
+	}
+}
+
+// WithOutput sets the generator to generate the output variant of the schemas, for messages
+// returned by servers.
+//
+// Fields with the REQUIRED google.api.field_behavior are not required, as the behavior only
+// applies to input, and input only fields are dropped. The IDs of the schemas are suffixed with
+// ".output".
+func WithOutput() GeneratorOption {
+	return func(p *Generator) {
+		p.variant = variantOutput
+	}
+}
+
+// WithPatch sets the generator to generate the patch variant of the schemas, for partial updates
+// of messages, e.g. with a google.protobuf.FieldMask.
+//
+// No field is required, including in nested messages and by required oneofs, and strict mode
+// does not require fields with implicit defaults. The types and values of the fields are
+// constrained as usual. The IDs of the schemas are suffixed with ".patch".
+func WithPatch() GeneratorOption {
+	return func(p *Generator) {
+		p.variant = variantPatch
+	}
+}
+
 // Generator is a JSON schema generator for protobuf messages.
 type Generator struct {
 	schema               map[protoreflect.FullName]*msgSchema
-	custom               map[protoreflect.FullName]func(protoreflect.MessageDescriptor, *validate.FieldRules, map[string]any) error
+	custom               map[protoreflect.FullName]customGenerator
+	anyTypes             []protoreflect.MessageDescriptor
+	anyPayloads          []anyPayload
+	services             []protoreflect.ServiceDescriptor
 	useJSONNames         bool
 	additionalProperties bool
 	strict               bool
 	bundle               bool
+	enumSchemas          bool
+	variant              variant
 }
 
 // NewGenerator creates a new JSON schema generator with the given options.
@@ -136,6 +224,9 @@ func (p *Generator) Add(desc protoreflect.MessageDescriptor) error {
 
 // Generate returns the generated JSON schema for all added message descriptors (and their dependencies if not bundling).
 func (p *Generator) Generate() map[protoreflect.FullName]map[string]any {
+	for _, payload := range p.anyPayloads {
+		p.generateAnyPayloadReferences(payload)
+	}
 	result := make(map[protoreflect.FullName]map[string]any, len(p.schema))
 	if !p.bundle {
 		// Not bundling, so return each schema separately.
@@ -189,18 +280,29 @@ func (p *Generator) bundleReferences(name protoreflect.FullName, defs map[string
 	}
 }
 
-// msgSchema is the internal representation of a protobuf message's schema.
+// msgSchema is the internal representation of a protobuf message's (or enum's) schema.
 type msgSchema struct {
 	// id is the unique identifier in the JSON schema for this message.
 	id     string
-	desc   protoreflect.MessageDescriptor
+	desc   protoreflect.Descriptor
 	schema map[string]any
-	// refs is a map of all referenced message schemas.
+	// refs is a map of all referenced message (and enum) schemas.
 	refs map[protoreflect.FullName]struct{}
 	// added is true if this schema was explicitly added and false if it is a dependency.
 	added bool
 }
 
+// addRef records a reference to the schema of the given message, if entry is not nil.
+func (s *msgSchema) addRef(name protoreflect.FullName) {
+	if s == nil {
+		return
+	}
+	if s.refs == nil {
+		s.refs = make(map[protoreflect.FullName]struct{})
+	}
+	s.refs[name] = struct{}{}
+}
+
 // getID returns the ID for the given descriptor.
 //
 // If bundleID is true, the ID for the bundle is returned.
@@ -217,6 +319,9 @@ func (p *Generator) getID(desc protoreflect.Descriptor, bundleID bool) string {
 	if p.strict {
 		result += ".strict"
 	}
+	if p.variant != variantDefault {
+		result += "." + string(p.variant)
+	}
 	if bundleID {
 		result += ".bundle"
 	}
@@ -253,35 +358,80 @@ func (p *Generator) generate(desc protoreflect.MessageDescriptor) (*msgSchema, e
 	// Generate the schema.
 	if custom, ok := p.custom[desc.FullName()]; ok {
 		// Custom generator.
-		return entry, custom(desc, nil, entry.schema)
+		return entry, custom(entry, desc, nil, entry.schema)
 	}
 	// Default generator.
-	return entry, p.generateMessage(entry)
+	return entry, p.generateMessage(entry, desc)
+}
+
+// generateEnum generates the standalone schema for an enum descriptor, if not already generated.
+func (p *Generator) generateEnum(desc protoreflect.EnumDescriptor) *msgSchema {
+	if entry, ok := p.schema[desc.FullName()]; ok {
+		return entry // Already generated.
+	}
+	entry := &msgSchema{
+		desc:   desc,
+		schema: make(map[string]any),
+		id:     p.getID(desc, false),
+	}
+	entry.schema["$schema"] = "https://json-schema.org/draft/2020-12/schema"
+	if !p.bundle {
+		entry.schema["$id"] = entry.id
+	}
+	entry.schema["title"] = nameToTitle(desc.Name())
+	p.setDescription(desc, entry.schema)
+	p.schema[desc.FullName()] = entry
+
+	names := make([]string, 0, desc.Values().Len())
+	var anyOf []map[string]any
+	for i := range desc.Values().Len() {
+		value := desc.Values().Get(i)
+		visibility := annotations.GetEnumValueOptions(value).GetVisibility()
+		switch {
+		case visibility == protoschemav1.Visibility_VISIBILITY_IGNORED:
+			// Not accepted by name.
+		case visibility == protoschemav1.Visibility_VISIBILITY_HIDDEN, annotations.IsDeprecated(value):
+			// Use a pattern so IDEs don't suggest the value.
+			anyOf = append(anyOf, map[string]any{"type": jsString, "pattern": "^" + string(value.Name()) + "$"})
+		default:
+			names = append(names, string(value.Name()))
+		}
+	}
+	anyOf = append([]map[string]any{{"type": jsString, "enum": names}}, anyOf...)
+	if !p.strict {
+		anyOf = append(anyOf, map[string]any{"type": jsInteger, "minimum": math.MinInt32, "maximum": math.MaxInt32})
+	}
+	if len(anyOf) == 1 {
+		maps.Copy(entry.schema, anyOf[0])
+	} else {
+		entry.schema["anyOf"] = anyOf
+	}
+	return entry
 }
 
-func (p *Generator) generateMessage(entry *msgSchema) error {
+func (p *Generator) generateMessage(entry *msgSchema, desc protoreflect.MessageDescriptor) error {
 	entry.schema["type"] = jsObject
-	p.setDescription(entry.desc, entry.schema)
+	p.setDescription(desc, entry.schema)
+	if err := p.setMessageAnnotations(desc, entry.schema); err != nil {
+		return fmt.Errorf("failed to generate message %q: %w", desc.FullName(), err)
+	}
 	var required []string
 	properties := make(map[string]any)
 	patternProperties := make(map[string]any)
-	for i := range entry.desc.Fields().Len() {
-		field := entry.desc.Fields().Get(i)
+	// The names accepted for each field, used to constrain oneofs.
+	fieldNames := make(map[protoreflect.Name][]string)
+	for i := range desc.Fields().Len() {
+		field := desc.Fields().Get(i)
 		visibility := p.shouldIgnoreField(field)
-		if visibility == FieldIgnore {
+		if visibility == FieldIgnore || p.isVariantExcluded(field) {
 			continue
 		}
 		rules, err := p.getFieldRules(field)
 		if err != nil {
 			return err
 		}
-		if (rules.GetRequired() && rules.GetIgnore() != validate.Ignore_IGNORE_IF_ZERO_VALUE) || // Required by validate rules.
-			(p.strict && p.hasImplicitDefault(field, field.IsList() || field.IsMap(), rules)) { // Required by strict mode.
-			if p.useJSONNames {
-				required = append(required, field.JSONName())
-			} else {
-				required = append(required, string(field.Name()))
-			}
+		if p.isFieldRequired(field, rules) {
+			required = append(required, p.getFieldName(field))
 		}
 
 		// Generate the schema.
@@ -293,9 +443,22 @@ func (p *Generator) generateMessage(entry *msgSchema) error {
 		aliases := p.addFieldProperties(field, visibility == FieldHide, fieldSchema, properties)
 		// Add any aliases to the pattern properties.
 		if !p.strict && len(aliases) > 0 {
-			pattern := "^(" + strings.Join(aliases, "|") + ")$"
+			quoted := make([]string, len(aliases))
+			for i, alias := range aliases {
+				quoted[i] = regexp.QuoteMeta(alias)
+			}
+			pattern := "^(" + strings.Join(quoted, "|") + ")$"
 			patternProperties[pattern] = fieldSchema
 		}
+
+		var names []string
+		if visibility != FieldHide {
+			names = append(names, p.getFieldName(field))
+		}
+		if !p.strict {
+			names = append(names, aliases...)
+		}
+		fieldNames[field.Name()] = names
 	}
 	entry.schema["properties"] = properties
 	entry.schema["additionalProperties"] = p.additionalProperties
@@ -305,15 +468,170 @@ func (p *Generator) generateMessage(entry *msgSchema) error {
 	if len(required) > 0 {
 		entry.schema["required"] = required
 	}
+
+	msgRules, err := protovalidate.ResolveMessageRules(desc)
+	if err != nil {
+		return err
+	}
+	var allOf []map[string]any
+	for i := range desc.Oneofs().Len() {
+		oneof := desc.Oneofs().Get(i)
+		if oneof.IsSynthetic() {
+			continue // Not a real oneof, just explicit presence.
+		}
+		oneofSchema, err := p.generateOneofValidation(oneof, fieldNames)
+		if err != nil {
+			return fmt.Errorf("failed to generate oneof %q: %w", oneof.FullName(), err)
+		}
+		if oneofSchema != nil {
+			allOf = append(allOf, oneofSchema)
+		}
+	}
+	for _, oneofRule := range msgRules.GetOneof() {
+		oneofSchema, err := p.generateMessageOneofValidation(desc, oneofRule, fieldNames)
+		if err != nil {
+			return err
+		}
+		if oneofSchema != nil {
+			allOf = append(allOf, oneofSchema)
+		}
+	}
+	celSchemas, celAnnotations := p.newMessageCELTranslator(desc, fieldNames).generateCELValidation(
+		getCELRules(msgRules.GetCel(), msgRules.GetCelExpression()))
+	allOf = append(allOf, celSchemas...)
+	appendAllOf(entry.schema, allOf...)
+	if len(celAnnotations) > 0 {
+		entry.schema["x-cel"] = celAnnotations
+	}
 	return nil
 }
 
+// isFieldRequired returns true if the given field must be present.
+func (p *Generator) isFieldRequired(field protoreflect.FieldDescriptor, rules *validate.FieldRules) bool {
+	switch {
+	case p.variant == variantPatch:
+		return false // Nothing is required in a partial update.
+	case rules.GetRequired() && rules.GetIgnore() != validate.Ignore_IGNORE_IF_ZERO_VALUE:
+		return true // Required by validate rules.
+	case p.variant != variantOutput && annotations.HasFieldBehavior(field, apiannotations.FieldBehavior_REQUIRED):
+		return true // Required by field behavior, which only applies to input.
+	default:
+		return p.strict && p.hasImplicitDefault(field, field.IsList() || field.IsMap(), rules) // Required by strict mode.
+	}
+}
+
+// getFieldName returns the primary name of the given field.
+func (p *Generator) getFieldName(field protoreflect.FieldDescriptor) string {
+	if p.useJSONNames {
+		return field.JSONName()
+	}
+	return string(field.Name())
+}
+
+// generateOneofValidation returns a schema that only allows one member of the oneof to be set.
+//
+// Every accepted name of every member is treated as a separate option, so setting a field
+// under both its proto and JSON name is rejected, just like protojson does. If the oneof is
+// required, exactly one option must be present, otherwise at most one.
+func (p *Generator) generateOneofValidation(oneof protoreflect.OneofDescriptor, fieldNames map[protoreflect.Name][]string) (map[string]any, error) {
+	rules, err := protovalidate.ResolveOneofRules(oneof)
+	if err != nil {
+		return nil, err
+	}
+	var options []map[string]any
+	for i := range oneof.Fields().Len() {
+		for _, name := range fieldNames[oneof.Fields().Get(i).Name()] {
+			options = append(options, map[string]any{"required": []string{name}})
+		}
+	}
+	return generateExclusiveValidation(options, rules.GetRequired() && p.variant != variantPatch), nil
+}
+
+// generateMessageOneofValidation returns a schema for a (buf.validate.message).oneof rule.
+//
+// Unlike a real oneof, the fields of the rule may not track presence. protovalidate considers
+// such a field unset when it has the zero value, so a present zero value does not count.
+func (p *Generator) generateMessageOneofValidation(
+	desc protoreflect.MessageDescriptor,
+	rule *validate.MessageOneofRule,
+	fieldNames map[protoreflect.Name][]string,
+) (map[string]any, error) {
+	var options []map[string]any
+	for _, fieldName := range rule.GetFields() {
+		field := desc.Fields().ByName(protoreflect.Name(fieldName))
+		if field == nil {
+			return nil, fmt.Errorf("field %q in message oneof rule not found in %q", fieldName, desc.FullName())
+		}
+		options = append(options, p.generateSetOptions(field, fieldNames[field.Name()])...)
+	}
+	return generateExclusiveValidation(options, rule.GetRequired() && p.variant != variantPatch), nil
+}
+
+// generateSetOptions returns one schema per accepted name of a field, each matching an
+// object where the field is set under that name, as protovalidate would see it.
+func (p *Generator) generateSetOptions(field protoreflect.FieldDescriptor, names []string) []map[string]any {
+	options := make([]map[string]any, 0, len(names))
+	for _, name := range names {
+		option := map[string]any{"required": []string{name}}
+		if notZero := p.generateNotZeroValidation(field); notZero != nil {
+			option["properties"] = map[string]any{name: notZero}
+		}
+		options = append(options, option)
+	}
+	return options
+}
+
+// generateNotZeroValidation returns a schema that rejects the zero value of a field without
+// presence, or nil if the field has presence.
+func (p *Generator) generateNotZeroValidation(field protoreflect.FieldDescriptor) map[string]any {
+	switch {
+	case field.IsList():
+		return map[string]any{"minItems": 1}
+	case field.IsMap():
+		return map[string]any{"minProperties": 1}
+	case field.HasPresence():
+		return nil
+	}
+	var zeros []any
+	switch field.Kind() {
+	case protoreflect.BoolKind:
+		zeros = append(zeros, false)
+	case protoreflect.StringKind, protoreflect.BytesKind:
+		zeros = append(zeros, "")
+	case protoreflect.EnumKind:
+		zeros = append(zeros, 0)
+		if zeroValue := field.Enum().Values().ByNumber(0); zeroValue != nil {
+			zeros = append(zeros, string(zeroValue.Name()))
+		}
+	default:
+		zeros = append(zeros, 0)
+		if !p.strict {
+			zeros = append(zeros, "0")
+		}
+	}
+	return map[string]any{"not": map[string]any{"enum": zeros}}
+}
+
+// generateExclusiveValidation returns a schema that allows at most one of the given options
+// to match, or exactly one if required is true.
+//
+// Returns nil if the schema would accept any object.
+func generateExclusiveValidation(options []map[string]any, required bool) map[string]any {
+	if len(options) == 0 || (len(options) == 1 && !required) {
+		return nil
+	}
+	if !required {
+		// Also allow none of the options to be present.
+		options = append(options, map[string]any{"not": map[string]any{"anyOf": slices.Clone(options)}})
+	}
+	return map[string]any{"oneOf": options}
+}
+
 func (p *Generator) addFieldProperties(
 	field protoreflect.FieldDescriptor,
 	hide bool,
 	fieldSchema map[string]any,
 	properties map[string]any) []string {
-	// TODO: Add an option to include custom alias.
 	aliases := make([]string, 0, 1)
 	if p.useJSONNames {
 		// Add the JSON name as the primary name.
@@ -326,18 +644,23 @@ func (p *Generator) addFieldProperties(
 		if field.JSONName() != string(field.Name()) {
 			aliases = append(aliases, string(field.Name()))
 		}
-		return aliases
-	}
-
-	// Add the proto name as the primary name.
-	if hide {
-		aliases = append(aliases, string(field.Name()))
 	} else {
-		properties[string(field.Name())] = fieldSchema
+		// Add the proto name as the primary name.
+		if hide {
+			aliases = append(aliases, string(field.Name()))
+		} else {
+			properties[string(field.Name())] = fieldSchema
+		}
+		// Add the JSON name as an alias.
+		if field.JSONName() != string(field.Name()) {
+			aliases = append(aliases, field.JSONName())
+		}
 	}
-	// Add the JSON name as an alias.
-	if field.JSONName() != string(field.Name()) {
-		aliases = append(aliases, field.JSONName())
+	// Add any custom aliases.
+	for _, alias := range annotations.GetFieldOptions(field).GetAliases() {
+		if alias != string(field.Name()) && alias != field.JSONName() && !slices.Contains(aliases, alias) {
+			aliases = append(aliases, alias)
+		}
 	}
 	return aliases
 }
@@ -376,10 +699,14 @@ func (p *Generator) generateField(entry *msgSchema, field protoreflect.FieldDesc
 	if err := p.generateFieldValidation(entry, field, false, rules, schema); err != nil {
 		return nil, err
 	}
+	if err := p.setFieldAnnotations(field, schema); err != nil {
+		return nil, err
+	}
 	return schema, nil
 }
 
 func (p *Generator) generateFieldValidation(entry *msgSchema, field protoreflect.FieldDescriptor, hasImplicitPresence bool, rules *validate.FieldRules, schema map[string]any) error {
+	p.generateFieldCELValidation(field, field.IsList(), rules, schema)
 	if field.IsList() {
 		schema["type"] = jsArray
 		if repeated := rules.GetRepeated(); repeated != nil {
@@ -389,18 +716,22 @@ func (p *Generator) generateFieldValidation(entry *msgSchema, field protoreflect
 			if repeated.HasMaxItems() {
 				schema["maxItems"] = repeated.GetMaxItems()
 			}
+			if repeated.GetUnique() {
+				schema["uniqueItems"] = true
+			}
 		}
 		items := make(map[string]any)
 		schema["items"] = items
 		schema = items
 		rules = rules.GetRepeated().GetItems()
 		hasImplicitPresence = true
+		p.generateFieldCELValidation(field, false, rules, schema)
 	}
 	switch field.Kind() {
 	case protoreflect.BoolKind:
 		p.generateBoolValidation(field, hasImplicitPresence, rules, schema)
 	case protoreflect.EnumKind:
-		p.generateEnumValidation(field, hasImplicitPresence, rules, schema)
+		p.generateEnumValidation(entry, field, hasImplicitPresence, rules, schema)
 	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
 		p.generateInt32Validation(field, hasImplicitPresence, rules, schema)
 	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
@@ -425,6 +756,14 @@ func (p *Generator) generateFieldValidation(entry *msgSchema, field protoreflect
 			if err != nil {
 				return err
 			}
+			if mapRules := rules.GetMap(); mapRules != nil {
+				if mapRules.HasMinPairs() {
+					schema["minProperties"] = mapRules.GetMinPairs()
+				}
+				if mapRules.HasMaxPairs() {
+					schema["maxProperties"] = mapRules.GetMaxPairs()
+				}
+			}
 			if err := p.generateFieldValidation(entry, field.MapKey(), true, rules.GetMap().GetKeys(), propertyNames); err != nil {
 				return err
 			}
@@ -435,7 +774,7 @@ func (p *Generator) generateFieldValidation(entry *msgSchema, field protoreflect
 			}
 			schema["additionalProperties"] = properties
 		} else {
-			return p.generateMessageValidation(entry, field, schema)
+			return p.generateMessageValidation(entry, field, rules, schema)
 		}
 	}
 	return nil
@@ -479,6 +818,15 @@ func (p *Generator) generateDefault(field protoreflect.FieldDescriptor, hasImpli
 	}
 }
 
+// appendAllOf adds the given schemas to the 'allOf' of the schema, keeping any already present.
+func appendAllOf(schema map[string]any, schemas ...map[string]any) {
+	if len(schemas) == 0 {
+		return
+	}
+	allOf, _ := schema["allOf"].([]map[string]any)
+	schema["allOf"] = append(allOf, schemas...)
+}
+
 func nameToTitle(name protoreflect.Name) string {
 	// Convert camel case to space separated words.
 	var result strings.Builder
@@ -508,11 +856,12 @@ func (p *Generator) generateBoolValidation(field protoreflect.FieldDescriptor, h
 
 type enumValueSelector struct {
 	remove bool
+	hide   bool
 	number int32
 	name   protoreflect.Name
 }
 
-func (p *Generator) generateEnumValidation(field protoreflect.FieldDescriptor, hasImplicitPresence bool, rules *validate.FieldRules, schema map[string]any) {
+func (p *Generator) generateEnumValidation(entry *msgSchema, field protoreflect.FieldDescriptor, hasImplicitPresence bool, rules *validate.FieldRules, schema map[string]any) {
 	allowZero := true
 	hideZero := false
 	if !field.HasPresence() && !hasImplicitPresence {
@@ -520,21 +869,26 @@ func (p *Generator) generateEnumValidation(field protoreflect.FieldDescriptor, h
 		if rules.GetRequired() && rules.GetIgnore() != validate.Ignore_IGNORE_IF_ZERO_VALUE {
 			// It is required, so zero is not allowed.
 			allowZero = false
-		} else if !p.strict {
+		} else if !p.strict && !p.enumSchemas {
 			// Zero is allowed, but absence is preferred.
 			hideZero = true
 		}
 	}
 
 	// Enumerate the values.
-	enumValues := make([]enumValueSelector, field.Enum().Values().Len())
+	enumValues := make([]enumValueSelector, 0, field.Enum().Values().Len())
 	for i := range field.Enum().Values().Len() {
 		val := field.Enum().Values().Get(i)
-		enumValues[i] = enumValueSelector{
+		visibility := annotations.GetEnumValueOptions(val).GetVisibility()
+		if visibility == protoschemav1.Visibility_VISIBILITY_IGNORED {
+			continue // Not accepted by name.
+		}
+		enumValues = append(enumValues, enumValueSelector{
 			remove: !allowZero && val.Number() == 0,
+			hide:   visibility == protoschemav1.Visibility_VISIBILITY_HIDDEN || annotations.IsDeprecated(val),
 			number: int32(val.Number()),
 			name:   val.Name(),
-		}
+		})
 	}
 
 	// Apply const.
@@ -562,6 +916,24 @@ func (p *Generator) generateEnumValidation(field protoreflect.FieldDescriptor, h
 		}
 	}
 
+	restricted := !p.strict && rules.GetEnum().GetDefinedOnly()
+	for _, enumValue := range enumValues {
+		restricted = restricted || enumValue.remove
+	}
+	if p.enumSchemas {
+		// Reference the standalone enum schema.
+		entry.addRef(field.Enum().FullName())
+		schema["$ref"] = p.generateEnum(field.Enum()).id
+		p.generateDefault(field, hasImplicitPresence, rules, schema)
+		if !restricted {
+			return
+		}
+		// Layer the field specific rules on top of the enum schema.
+		restriction := make(map[string]any)
+		appendAllOf(schema, restriction)
+		schema = restriction
+	}
+
 	anyOf := []map[string]any{}
 
 	// Add the selected enum names to the schema, in order of declaration.
@@ -572,8 +944,8 @@ func (p *Generator) generateEnumValidation(field protoreflect.FieldDescriptor, h
 			continue
 		}
 		int32Values = append(int32Values, enumValue.number)
-		if hideZero && enumValue.number == 0 {
-			// Use a pattern so IDEs don't suggest the zero value, but it is considered valid when explicitly specified.
+		if enumValue.hide || (hideZero && enumValue.number == 0) {
+			// Use a pattern so IDEs don't suggest the value, but it is considered valid when explicitly specified.
 			anyOf = append(anyOf, map[string]any{"type": jsString, "pattern": "^" + string(enumValue.name) + "$"})
 		} else {
 			stringValues = append(stringValues, string(enumValue.name))
@@ -604,6 +976,9 @@ func (p *Generator) generateEnumValidation(field protoreflect.FieldDescriptor, h
 	} else {
 		schema["anyOf"] = anyOf
 	}
+	if p.enumSchemas {
+		return
+	}
 
 	schema["title"] = nameToTitle(field.Enum().Name())
 	p.generateDefault(field, hasImplicitPresence, rules, schema)
@@ -1372,15 +1747,16 @@ func (p *Generator) generateBytesValidation(field protoreflect.FieldDescriptor,
 	}
 }
 
-func (p *Generator) generateMessageValidation(entry *msgSchema, field protoreflect.FieldDescriptor, schema map[string]any) error {
-	// Create a reference to the message type.
-	if entry != nil {
-		if entry.refs == nil {
-			entry.refs = map[protoreflect.FullName]struct{}{field.Message().FullName(): {}}
-		} else {
-			entry.refs[field.Message().FullName()] = struct{}{}
-		}
+func (p *Generator) generateMessageValidation(entry *msgSchema, field protoreflect.FieldDescriptor, rules *validate.FieldRules, schema map[string]any) error {
+	if field.Message().FullName() == anyFullName && (rules.GetAny() != nil || len(p.anyTypes) > 0) {
+		return p.generateAnyValidation(entry, field, rules, schema)
 	}
+	if custom, ok := p.custom[field.Message().FullName()]; ok && (rules.GetDuration() != nil || rules.GetTimestamp() != nil) {
+		// The rules are specific to this field, so generate the schema inline.
+		return custom(entry, field.Message(), rules, schema)
+	}
+	// Create a reference to the message type.
+	entry.addRef(field.Message().FullName())
 	schema["$ref"] = p.getRef(field)
 	// Ensure the schema for the message type is generated.
 	_, err := p.generate(field.Message())
@@ -1388,57 +1764,290 @@ func (p *Generator) generateMessageValidation(entry *msgSchema, field protorefle
 }
 
 func (p *Generator) generateWrapperValidation(
+	entry *msgSchema,
 	desc protoreflect.MessageDescriptor,
 	rules *validate.FieldRules,
 	schema map[string]any,
 ) error {
 	field := desc.Fields().Get(0)
 	p.setDescription(field, schema)
-	return p.generateFieldValidation(nil, field, true, rules, schema)
+	return p.generateFieldValidation(entry, field, true, rules, schema)
 }
 
-func (p *Generator) makeWktGenerators() map[protoreflect.FullName]func(protoreflect.MessageDescriptor, *validate.FieldRules, map[string]any) error {
-	var result = make(map[protoreflect.FullName]func(protoreflect.MessageDescriptor, *validate.FieldRules, map[string]any) error)
-	result["google.protobuf.Any"] = func(_ protoreflect.MessageDescriptor, _ *validate.FieldRules, schema map[string]any) error { // nolint: unparam
-		schema["type"] = jsObject
-		schema["properties"] = map[string]any{
-			"@type": map[string]any{
-				"type": "string",
-			},
-		}
-		return nil
+// generateRefValidation sets the schema to a reference to the schema of the given message.
+func (p *Generator) generateRefValidation(entry *msgSchema, desc protoreflect.MessageDescriptor, schema map[string]any) error {
+	entry.addRef(desc.FullName())
+	schema["$ref"] = p.getID(desc, false)
+	// Ensure the schema for the message type is generated.
+	_, err := p.generate(desc)
+	return err
+}
+
+// generateFieldMaskValidation generates the schema for a google.protobuf.FieldMask.
+//
+// The paths are comma separated, with each path a dot separated list of lowerCamelCase field
+// names. If not strict, the proto field names are also allowed.
+func (p *Generator) generateFieldMaskValidation(_ *msgSchema, _ protoreflect.MessageDescriptor, _ *validate.FieldRules, schema map[string]any) error {
+	schema["type"] = jsString
+	name := "[a-z][a-zA-Z0-9]*"
+	if !p.strict {
+		name = "[a-zA-Z_][a-zA-Z0-9_]*"
 	}
+	path := name + "(\\." + name + ")*"
+	schema["pattern"] = "^(" + path + "(," + path + ")*)?$"
+	return nil
+}
 
-	result["google.protobuf.Duration"] = func(_ protoreflect.MessageDescriptor, _ *validate.FieldRules, schema map[string]any) error { // nolint: unparam
-		schema["type"] = jsString
-		schema["format"] = "duration"
-		return nil
+// generateValueValidation generates the schema for a google.protobuf.Value, which can be any
+// JSON value.
+func (p *Generator) generateValueValidation(entry *msgSchema, desc protoreflect.MessageDescriptor, _ *validate.FieldRules, schema map[string]any) error {
+	structSchema := make(map[string]any)
+	if err := p.generateRefValidation(entry, desc.Fields().ByName("struct_value").Message(), structSchema); err != nil {
+		return err
+	}
+	listSchema := make(map[string]any)
+	if err := p.generateRefValidation(entry, desc.Fields().ByName("list_value").Message(), listSchema); err != nil {
+		return err
+	}
+	schema["anyOf"] = []map[string]any{
+		{"type": jsNull},
+		{"type": jsNumber},
+		{"type": jsString},
+		{"type": jsBoolean},
+		structSchema,
+		listSchema,
 	}
-	result["google.protobuf.FieldMask"] = func(_ protoreflect.MessageDescriptor, _ *validate.FieldRules, schema map[string]any) error { // nolint: unparam
-		schema["type"] = jsString
-		return nil
+	return nil
+}
+
+// generateListValueValidation generates the schema for a google.protobuf.ListValue.
+func (p *Generator) generateListValueValidation(entry *msgSchema, desc protoreflect.MessageDescriptor, _ *validate.FieldRules, schema map[string]any) error {
+	schema["type"] = jsArray
+	items := make(map[string]any)
+	schema["items"] = items
+	return p.generateRefValidation(entry, desc.Fields().ByName("values").Message(), items)
+}
+
+// generateStructValidation generates the schema for a google.protobuf.Struct.
+func (p *Generator) generateStructValidation(entry *msgSchema, desc protoreflect.MessageDescriptor, _ *validate.FieldRules, schema map[string]any) error {
+	schema["type"] = jsObject
+	additionalProperties := make(map[string]any)
+	schema["additionalProperties"] = additionalProperties
+	return p.generateRefValidation(entry, desc.Fields().ByName("fields").MapValue().Message(), additionalProperties)
+}
+
+// generateDurationValidation generates the schema for a google.protobuf.Duration.
+//
+// JSON schema cannot compare durations, so range rules are added as x- annotations. Exact
+// values are matched with an enum, or a pattern that also allows trailing zeros if not strict.
+func (p *Generator) generateDurationValidation(_ *msgSchema, _ protoreflect.MessageDescriptor, rules *validate.FieldRules, schema map[string]any) error {
+	schema["type"] = jsString
+	if p.strict {
+		// protojson always outputs 0, 3, 6 or 9 fractional digits.
+		schema["pattern"] = "^-?(0|[1-9][0-9]*)(\\.([0-9]{3}){1,3})?s$"
+	} else {
+		schema["pattern"] = "^[-+]?((0|[1-9][0-9]*)(\\.[0-9]{0,9})?|\\.[0-9]{1,9})s$"
 	}
-	result["google.protobuf.Timestamp"] = func(_ protoreflect.MessageDescriptor, _ *validate.FieldRules, schema map[string]any) error { // nolint: unparam
-		schema["type"] = jsString
-		schema["format"] = "date-time"
+	durationRules := rules.GetDuration()
+	if durationRules == nil {
 		return nil
 	}
+	var err error
+	setDuration := func(key string, value *durationpb.Duration) {
+		if err == nil {
+			schema[key], err = formatWellKnownType(value)
+		}
+	}
+	if durationRules.HasGt() {
+		setDuration("x-exclusiveMinimum", durationRules.GetGt())
+	} else if durationRules.HasGte() {
+		setDuration("x-minimum", durationRules.GetGte())
+	}
+	if durationRules.HasLt() {
+		setDuration("x-exclusiveMaximum", durationRules.GetLt())
+	} else if durationRules.HasLte() {
+		setDuration("x-maximum", durationRules.GetLte())
+	}
+	if err != nil {
+		return err
+	}
+	switch {
+	case durationRules.HasConst():
+		values, err := p.generateDurationValues([]*durationpb.Duration{durationRules.GetConst()})
+		if err != nil {
+			return err
+		}
+		maps.Copy(schema, values)
+	case len(durationRules.GetIn()) > 0:
+		values, err := p.generateDurationValues(durationRules.GetIn())
+		if err != nil {
+			return err
+		}
+		maps.Copy(schema, values)
+	}
+	if len(durationRules.GetNotIn()) > 0 {
+		values, err := p.generateDurationValues(durationRules.GetNotIn())
+		if err != nil {
+			return err
+		}
+		schema["not"] = values
+	}
+	return nil
+}
 
-	result["google.protobuf.Value"] = func(_ protoreflect.MessageDescriptor, _ *validate.FieldRules, _ map[string]any) error { // nolint: unparam
-		return nil
+// generateDurationValues returns a schema that only matches the given durations.
+func (p *Generator) generateDurationValues(durations []*durationpb.Duration) (map[string]any, error) {
+	values := make([]string, 0, len(durations))
+	patterns := make([]string, 0, len(durations))
+	for _, duration := range durations {
+		value, err := formatWellKnownType(duration)
+		if err != nil {
+			return nil, err
+		}
+		values = append(values, value)
+		// Allow the other representations protojson accepts: an explicit plus sign, an
+		// omitted zero before the decimal point, and any number of trailing zeros.
+		seconds, fraction, _ := strings.Cut(strings.TrimSuffix(value, "s"), ".")
+		fraction = strings.TrimRight(fraction, "0")
+		sign := "\\+?"
+		if strings.HasPrefix(seconds, "-") {
+			sign, seconds = "-", seconds[1:]
+		}
+		if fraction == "" {
+			patterns = append(patterns, sign+seconds+"(\\.0{0,9})?s")
+			continue
+		}
+		if seconds == "0" {
+			seconds = "0?"
+		}
+		patterns = append(patterns, fmt.Sprintf("%s%s\\.%s0{0,%d}s", sign, seconds, fraction, 9-len(fraction)))
 	}
-	result["google.protobuf.ListValue"] = func(_ protoreflect.MessageDescriptor, _ *validate.FieldRules, schema map[string]any) error { // nolint: unparam
-		schema["type"] = jsArray
-		return nil
+	if p.strict {
+		return map[string]any{"enum": values}, nil
 	}
-	result["google.protobuf.NullValue"] = func(_ protoreflect.MessageDescriptor, _ *validate.FieldRules, schema map[string]any) error { // nolint: unparam
-		schema["type"] = jsNull
+	return map[string]any{"pattern": "^(" + strings.Join(patterns, "|") + ")$"}, nil
+}
+
+// generateTimestampValidation generates the schema for a google.protobuf.Timestamp.
+//
+// Bounds are added with the formatMinimum and formatMaximum keywords, which only some
+// validators assert. Rules relative to the current time are added as x- annotations.
+func (p *Generator) generateTimestampValidation(_ *msgSchema, _ protoreflect.MessageDescriptor, rules *validate.FieldRules, schema map[string]any) error {
+	schema["type"] = jsString
+	schema["format"] = "date-time"
+	// RFC 3339, limited to the range protojson accepts.
+	const datePattern = "(000[1-9]|00[1-9][0-9]|0[1-9][0-9]{2}|[1-9][0-9]{3})-(0[1-9]|1[0-2])-(0[1-9]|[12][0-9]|3[01])"
+	const timePattern = "([01][0-9]|2[0-3]):[0-5][0-9]:[0-5][0-9]"
+	if p.strict {
+		// protojson always outputs UTC with 0, 3, 6 or 9 fractional digits.
+		schema["pattern"] = "^" + datePattern + "T" + timePattern + "(\\.([0-9]{3}){1,3})?Z$"
+	} else {
+		schema["pattern"] = "^" + datePattern + "T" + timePattern + "(\\.[0-9]{1,9})?(Z|[+-]([01][0-9]|2[0-3]):[0-5][0-9])$"
+	}
+	timestampRules := rules.GetTimestamp()
+	if timestampRules == nil {
 		return nil
 	}
-	result["google.protobuf.Struct"] = func(_ protoreflect.MessageDescriptor, _ *validate.FieldRules, schema map[string]any) error { // nolint: unparam
+	var err error
+	format := func(value *timestamppb.Timestamp) string {
+		var result string
+		if err == nil {
+			result, err = formatWellKnownType(value)
+		}
+		return result
+	}
+	if timestampRules.HasConst() {
+		value := format(timestampRules.GetConst())
+		if p.strict {
+			schema["enum"] = []string{value}
+		} else {
+			schema["formatMinimum"] = value
+			schema["formatMaximum"] = value
+		}
+		return err
+	}
+	minSchema := make(map[string]any)
+	switch {
+	case timestampRules.HasGt():
+		minSchema["formatExclusiveMinimum"] = format(timestampRules.GetGt())
+	case timestampRules.HasGte():
+		minSchema["formatMinimum"] = format(timestampRules.GetGte())
+	}
+	maxSchema := make(map[string]any)
+	switch {
+	case timestampRules.HasLt():
+		maxSchema["formatExclusiveMaximum"] = format(timestampRules.GetLt())
+	case timestampRules.HasLte():
+		maxSchema["formatMaximum"] = format(timestampRules.GetLte())
+	}
+	if err != nil {
+		return err
+	}
+	lowerBound := timestampRules.GetGt()
+	if lowerBound == nil {
+		lowerBound = timestampRules.GetGte()
+	}
+	upperBound := timestampRules.GetLt()
+	if upperBound == nil {
+		upperBound = timestampRules.GetLte()
+	}
+	if lowerBound != nil && upperBound != nil && upperBound.AsTime().Before(lowerBound.AsTime()) {
+		// An exclusive range, so the value must be outside of the bounds.
+		schema["anyOf"] = []map[string]any{minSchema, maxSchema}
+	} else {
+		maps.Copy(schema, minSchema)
+		maps.Copy(schema, maxSchema)
+	}
+	if timestampRules.GetLtNow() {
+		schema["x-ltNow"] = true
+	}
+	if timestampRules.GetGtNow() {
+		schema["x-gtNow"] = true
+	}
+	if timestampRules.HasWithin() {
+		if schema["x-within"], err = formatWellKnownType(timestampRules.GetWithin()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// formatWellKnownType returns the protojson string representation of a well-known type.
+func formatWellKnownType(msg proto.Message) (string, error) {
+	data, err := protojson.Marshal(msg)
+	if err != nil {
+		return "", fmt.Errorf("failed to format %q: %w", msg.ProtoReflect().Descriptor().FullName(), err)
+	}
+	var result string
+	if err := json.Unmarshal(data, &result); err != nil {
+		return "", fmt.Errorf("failed to format %q: %w", msg.ProtoReflect().Descriptor().FullName(), err)
+	}
+	return result, nil
+}
+
+func (p *Generator) makeWktGenerators() map[protoreflect.FullName]customGenerator {
+	var result = make(map[protoreflect.FullName]customGenerator)
+	result["google.protobuf.Any"] = func(_ *msgSchema, _ protoreflect.MessageDescriptor, _ *validate.FieldRules, schema map[string]any) error { // nolint: unparam
 		schema["type"] = jsObject
+		schema["properties"] = map[string]any{
+			"@type": map[string]any{
+				"type": "string",
+			},
+		}
+		return nil
+	}
+
+	result["google.protobuf.Duration"] = p.generateDurationValidation
+	result["google.protobuf.FieldMask"] = p.generateFieldMaskValidation
+	result["google.protobuf.Timestamp"] = p.generateTimestampValidation
+
+	result["google.protobuf.Value"] = p.generateValueValidation
+	result["google.protobuf.ListValue"] = p.generateListValueValidation
+	result["google.protobuf.NullValue"] = func(_ *msgSchema, _ protoreflect.MessageDescriptor, _ *validate.FieldRules, schema map[string]any) error { // nolint: unparam
+		schema["type"] = jsNull
 		return nil
 	}
+	result["google.protobuf.Struct"] = p.generateStructValidation
 
 	result["google.protobuf.BoolValue"] = p.generateWrapperValidation
 	result["google.protobuf.BytesValue"] = p.generateWrapperValidation
@@ -1453,15 +2062,10 @@ func (p *Generator) makeWktGenerators() map[protoreflect.FullName]func(protorefl
 }
 
 func (p *Generator) shouldIgnoreField(fdesc protoreflect.FieldDescriptor) FieldVisibility {
-	const ignoreComment = "jsonschema:ignore"
-	const hideComment = "jsonschema:hide"
-	srcLoc := fdesc.ParentFile().SourceLocations().ByDescriptor(fdesc)
-	switch {
-	case strings.Contains(srcLoc.LeadingComments, ignoreComment),
-		strings.Contains(srcLoc.TrailingComments, ignoreComment):
+	switch annotations.GetFieldVisibility(fdesc) {
+	case protoschemav1.Visibility_VISIBILITY_IGNORED:
 		return FieldIgnore
-	case strings.Contains(srcLoc.LeadingComments, hideComment),
-		strings.Contains(srcLoc.TrailingComments, hideComment):
+	case protoschemav1.Visibility_VISIBILITY_HIDDEN:
 		return FieldHide
 	default:
 		return FieldVisible
@@ -16,14 +16,22 @@ package jsonschema
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
 
+	"buf.build/go/protovalidate"
+	"github.com/bufbuild/protocompile"
 	"github.com/bufbuild/protoschema-plugins/internal/protoschema/golden"
 	"github.com/santhosh-tekuri/jsonschema/v6"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/dynamicpb"
 	"gopkg.in/yaml.v3"
 )
 
@@ -67,6 +75,872 @@ func TestTitle(t *testing.T) {
 	require.Equal(t, "FOO", nameToTitle("FOO"))
 }
 
+func TestAnyTypes(t *testing.T) {
+	t.Parallel()
+	testDescs, err := golden.GetTestDescriptors("../../testdata")
+	require.NoError(t, err)
+	var constraintDesc, productDesc protoreflect.MessageDescriptor
+	for _, testDesc := range testDescs {
+		switch testDesc.FullName() {
+		case "buf.protoschema.test.v1.ConstraintTest":
+			constraintDesc = testDesc
+		case "buf.protoschema.test.v1.Product":
+			productDesc = testDesc
+		}
+	}
+	generator := NewGenerator(WithAnyTypes(productDesc))
+	err = generator.Add(constraintDesc)
+	require.NoError(t, err)
+	schema := generator.Generate()[constraintDesc.FullName()]
+	properties, ok := schema["properties"].(map[string]any)
+	require.True(t, ok)
+
+	// The registered types are used when there is no any.in rule.
+	anySchema, ok := properties["any_not_in"].(map[string]any)
+	require.True(t, ok)
+	require.Equal(t, map[string]any{
+		"type": jsString,
+		"enum": []string{"type.googleapis.com/buf.protoschema.test.v1.Product"},
+	}, anySchema["properties"].(map[string]any)["@type"]) //nolint:forcetypeassert
+	require.Len(t, anySchema["allOf"], 1)
+
+	// The any.in rule takes precedence.
+	anySchema, ok = properties["any_in"].(map[string]any)
+	require.True(t, ok)
+	require.Equal(t, map[string]any{
+		"type": jsString,
+		"enum": []string{
+			"type.googleapis.com/buf.protoschema.test.v1.ConstraintTest.CelRules",
+			"type.googleapis.com/google.protobuf.Duration",
+		},
+	}, anySchema["properties"].(map[string]any)["@type"]) //nolint:forcetypeassert
+}
+
+func TestEnumSchemas(t *testing.T) {
+	t.Parallel()
+	testDescs, err := golden.GetTestDescriptors("../../testdata")
+	require.NoError(t, err)
+	var desc protoreflect.MessageDescriptor
+	for _, testDesc := range testDescs {
+		if testDesc.FullName() == "buf.protoschema.test.v1.ConstraintTest" {
+			desc = testDesc
+		}
+	}
+	generator := NewGenerator(WithEnumSchemas(), WithStrict(), WithBundle())
+	err = generator.Add(desc)
+	require.NoError(t, err)
+	schema := generator.Generate()[desc.FullName()]
+	defs, ok := schema["$defs"].(map[string]any)
+	require.True(t, ok)
+
+	// The enum is generated once.
+	enumID := "buf.protoschema.test.v1.ConstraintTest.Enum.schema.strict.json"
+	require.Equal(t, map[string]any{
+		"$schema": "https://json-schema.org/draft/2020-12/schema",
+		"title":   "Enum",
+		"type":    jsString,
+		"enum":    []string{"ENUM_UNSPECIFIED", "ENUM_VAL1", "ENUM_VAL2", "ENUM_VAL7"},
+	}, defs[enumID])
+
+	// Fields reference the enum, with their rules layered on top.
+	properties := defs["buf.protoschema.test.v1.ConstraintTest.schema.strict.json"].(map[string]any)["properties"].(map[string]any) //nolint:forcetypeassert
+	require.Equal(t, map[string]any{
+		"$ref": defsPrefix + enumID,
+	}, properties["defined_only_enum"])
+	require.Equal(t, map[string]any{
+		"$ref": defsPrefix + enumID,
+		"allOf": []map[string]any{
+			{"type": jsString, "enum": []string{"ENUM_VAL1", "ENUM_VAL2"}},
+		},
+	}, properties["in_enum"])
+}
+
+func TestAppendAllOf(t *testing.T) {
+	t.Parallel()
+	schema := map[string]any{}
+	appendAllOf(schema)
+	require.NotContains(t, schema, "allOf")
+	appendAllOf(schema, map[string]any{"minLength": 1})
+	appendAllOf(schema, map[string]any{"maxLength": 2}, map[string]any{"pattern": "^a"})
+	require.Equal(t, []map[string]any{
+		{"minLength": 1},
+		{"maxLength": 2},
+		{"pattern": "^a"},
+	}, schema["allOf"])
+}
+
+func TestOpenAPI(t *testing.T) {
+	t.Parallel()
+	testDescs, err := golden.GetTestDescriptors("../../testdata")
+	require.NoError(t, err)
+	generator := NewGenerator()
+	for _, testDesc := range testDescs {
+		err = generator.Add(testDesc)
+		require.NoError(t, err)
+	}
+	documents, err := generator.GenerateOpenAPI()
+	require.NoError(t, err)
+	require.Len(t, documents, 3)
+	document, ok := documents["buf.protoschema.test.v1.schema.openapi.json"]
+	require.True(t, ok)
+	require.Equal(t, "3.1.0", document["openapi"])
+	schemas := document["components"].(map[string]any)["schemas"].(map[string]any) //nolint:forcetypeassert
+	require.Contains(t, schemas, "buf.protoschema.test.v1.Product.Location")
+	require.Contains(t, schemas, "google.protobuf.Duration")
+	require.Equal(t, map[string]any{
+		"$ref":        "#/components/schemas/buf.protoschema.test.v1.Product.Location",
+		"description": "The location of the product.",
+	}, schemas["buf.protoschema.test.v1.Product"].(map[string]any)["properties"].(map[string]any)["location"]) //nolint:forcetypeassert
+
+	// The components validate the same as the generated schemas.
+	data, err := json.Marshal(document)
+	require.NoError(t, err)
+	documentData, err := jsonschema.UnmarshalJSON(strings.NewReader(string(data)))
+	require.NoError(t, err)
+	compiler := jsonschema.NewCompiler()
+	err = compiler.AddResource("file:///openapi.json", documentData)
+	require.NoError(t, err)
+	schema, err := compiler.Compile("file:///openapi.json#/components/schemas/buf.protoschema.test.v1.ConstraintTests")
+	require.NoError(t, err)
+
+	yamlData, err := os.ReadFile(filepath.FromSlash("../../testdata/jsonschema-doc/test.ConstraintTests.yaml"))
+	require.NoError(t, err)
+	var jsonData map[string]any
+	err = yaml.Unmarshal(yamlData, &jsonData)
+	require.NoError(t, err)
+	assertValidation(t, schema, jsonData, filepath.FromSlash("../../testdata/jsonschema-doc/test.ConstraintTests.txt"))
+}
+
+func TestOpenAPIPaths(t *testing.T) {
+	t.Parallel()
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.CompositeResolver{
+			protocompile.WithStandardImports(&protocompile.SourceResolver{
+				Accessor: protocompile.SourceAccessorFromMap(map[string]string{
+					"library/v1/library.proto": `
+						syntax = "proto3";
+						package library.v1;
+						import "google/api/annotations.proto";
+						import "google/protobuf/empty.proto";
+						import "google/protobuf/field_mask.proto";
+						message Book {
+						  string name = 1;
+						  string title = 2;
+						}
+						message GetBookRequest {
+						  string name = 1;
+						  google.protobuf.FieldMask read_mask = 2;
+						  map<string, string> labels = 3;
+						  Book filter = 4;
+						}
+						message UpdateBookRequest {
+						  Book book = 1;
+						  google.protobuf.FieldMask update_mask = 2;
+						}
+						message ListBooksResponse {
+						  repeated Book books = 1;
+						}
+						service LibraryService {
+						  // Get a book.
+						  //
+						  // Returns the book with the given name.
+						  rpc GetBook(GetBookRequest) returns (Book) {
+						    option (google.api.http) = {
+						      get: "/v1/{name=shelves/*/books/*}"
+						      additional_bindings { get: "/v1/books/{name}" }
+						    };
+						  }
+						  rpc UpdateBook(UpdateBookRequest) returns (Book) {
+						    option (google.api.http) = {
+						      patch: "/v1/{book.name=shelves/*/books/*}"
+						      body: "book"
+						    };
+						  }
+						  rpc ListBooks(google.protobuf.Empty) returns (ListBooksResponse) {
+						    option (google.api.http) = {
+						      get: "/v1/books"
+						      response_body: "books"
+						    };
+						  }
+						  rpc DeleteBook(GetBookRequest) returns (google.protobuf.Empty);
+						  rpc WatchBooks(google.protobuf.Empty) returns (stream Book);
+						}
+					`,
+				}),
+			}),
+			protocompile.ResolverFunc(func(path string) (protocompile.SearchResult, error) {
+				desc, err := protoregistry.GlobalFiles.FindFileByPath(path)
+				return protocompile.SearchResult{Desc: desc}, err
+			}),
+		},
+		SourceInfoMode: protocompile.SourceInfoStandard,
+	}
+	files, err := compiler.Compile(t.Context(), "library/v1/library.proto")
+	require.NoError(t, err)
+	generator := NewGenerator(WithStrict())
+	err = generator.AddService(files[0].Services().Get(0))
+	require.NoError(t, err)
+	documents, err := generator.GenerateOpenAPI()
+	require.NoError(t, err)
+	document, ok := documents["library.v1.schema.strict.openapi.json"]
+	require.True(t, ok)
+	schemas := document["components"].(map[string]any)["schemas"].(map[string]any) //nolint:forcetypeassert
+	require.Contains(t, schemas, "library.v1.Book")
+	require.Contains(t, schemas, "google.protobuf.Empty")
+	paths, ok := document["paths"].(map[string]any)
+	require.True(t, ok)
+	require.Len(t, paths, 5)
+
+	bookRef := map[string]any{"$ref": "#/components/schemas/library.v1.Book"}
+	require.Equal(t, map[string]any{
+		"get": map[string]any{
+			"operationId": "library.v1.LibraryService.GetBook",
+			"tags":        []string{"LibraryService"},
+			"summary":     "Get a book.",
+			"description": "Returns the book with the given name.",
+			"parameters": []map[string]any{
+				{"name": "name", "in": "path", "required": true, "schema": map[string]any{"type": jsString}},
+				{"name": "read_mask", "in": "query", "schema": map[string]any{"$ref": "#/components/schemas/google.protobuf.FieldMask"}},
+			},
+			"responses": map[string]any{
+				"200": map[string]any{
+					"description": "OK",
+					"content":     map[string]any{"application/json": map[string]any{"schema": bookRef}},
+				},
+			},
+		},
+	}, paths["/v1/{name}"])
+	require.Equal(t, "library.v1.LibraryService.GetBook_1", paths["/v1/books/{name}"].(map[string]any)["get"].(map[string]any)["operationId"]) //nolint:forcetypeassert
+
+	// The body is a field of the request, and the path variable a field of the body.
+	updateBook := paths["/v1/{book.name}"].(map[string]any)["patch"].(map[string]any) //nolint:forcetypeassert
+	require.Equal(t, map[string]any{
+		"required": true,
+		"content":  map[string]any{"application/json": map[string]any{"schema": bookRef}},
+	}, updateBook["requestBody"])
+	require.Equal(t, []map[string]any{
+		{"name": "book.name", "in": "path", "required": true, "schema": map[string]any{"type": jsString}},
+		{"name": "update_mask", "in": "query", "schema": map[string]any{"$ref": "#/components/schemas/google.protobuf.FieldMask"}},
+	}, updateBook["parameters"])
+
+	// The response is a field of the response message.
+	listBooks := paths["/v1/books"].(map[string]any)["get"].(map[string]any) //nolint:forcetypeassert
+	require.Equal(t, map[string]any{
+		"200": map[string]any{
+			"description": "OK",
+			"content": map[string]any{"application/json": map[string]any{"schema": map[string]any{
+				"type":  jsArray,
+				"items": bookRef,
+			}}},
+		},
+	}, listBooks["responses"])
+
+	// Methods without a rule use the Connect protocol.
+	deleteBook := paths["/library.v1.LibraryService/DeleteBook"].(map[string]any)["post"].(map[string]any) //nolint:forcetypeassert
+	require.Equal(t, map[string]any{
+		"required": true,
+		"content": map[string]any{"application/json": map[string]any{"schema": map[string]any{
+			"$ref": "#/components/schemas/library.v1.GetBookRequest",
+		}}},
+	}, deleteBook["requestBody"])
+}
+
+func TestWellKnownTypeRules(t *testing.T) {
+	t.Parallel()
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.CompositeResolver{
+			protocompile.WithStandardImports(&protocompile.SourceResolver{
+				Accessor: protocompile.SourceAccessorFromMap(map[string]string{
+					"shop/v1/order.proto": `
+						syntax = "proto3";
+						package shop.v1;
+						import "buf/validate/validate.proto";
+						import "google/protobuf/duration.proto";
+						import "google/protobuf/wrappers.proto";
+						message Order {
+						  google.protobuf.Duration ttl = 1 [(buf.validate.field).duration.const = {seconds: 60}];
+						  google.protobuf.Int32Value quantity = 2 [(buf.validate.field).int32.gt = 0];
+						}
+					`,
+				}),
+			}),
+			protocompile.ResolverFunc(func(path string) (protocompile.SearchResult, error) {
+				desc, err := protoregistry.GlobalFiles.FindFileByPath(path)
+				return protocompile.SearchResult{Desc: desc}, err
+			}),
+		},
+	}
+	files, err := compiler.Compile(t.Context(), "shop/v1/order.proto")
+	require.NoError(t, err)
+	generator := NewGenerator(WithStrict())
+	err = generator.Add(files[0].Messages().Get(0))
+	require.NoError(t, err)
+	schema := generator.Generate()["shop.v1.Order"]
+	properties := schema["properties"].(map[string]any) //nolint:forcetypeassert
+
+	// Duration and Timestamp rules are specific to the field, so the schema is inlined.
+	require.NotContains(t, properties["ttl"], "$ref")
+	require.Equal(t, []string{"60s"}, properties["ttl"].(map[string]any)["enum"]) //nolint:forcetypeassert
+	// Other well-known types reference the shared schema.
+	require.Equal(t, map[string]any{"$ref": "google.protobuf.Int32Value.schema.strict.json"}, properties["quantity"])
+}
+
+func TestFieldBehavior(t *testing.T) {
+	t.Parallel()
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.CompositeResolver{
+			protocompile.WithStandardImports(&protocompile.SourceResolver{
+				Accessor: protocompile.SourceAccessorFromMap(map[string]string{
+					"library/v1/book.proto": `
+						syntax = "proto3";
+						package library.v1;
+						import "google/api/field_behavior.proto";
+						message Book {
+						  option deprecated = true;
+						  string name = 1 [(google.api.field_behavior) = IDENTIFIER];
+						  string title = 2 [(google.api.field_behavior) = REQUIRED];
+						  string create_time = 3 [(google.api.field_behavior) = OUTPUT_ONLY];
+						  string etag = 4 [(google.api.field_behavior) = INPUT_ONLY];
+						  string isbn = 5 [(google.api.field_behavior) = IMMUTABLE, deprecated = true];
+						}
+					`,
+				}),
+			}),
+			protocompile.ResolverFunc(func(path string) (protocompile.SearchResult, error) {
+				desc, err := protoregistry.GlobalFiles.FindFileByPath(path)
+				return protocompile.SearchResult{Desc: desc}, err
+			}),
+		},
+	}
+	files, err := compiler.Compile(t.Context(), "library/v1/book.proto")
+	require.NoError(t, err)
+	generator := NewGenerator(WithStrict())
+	err = generator.Add(files[0].Messages().Get(0))
+	require.NoError(t, err)
+	schema := generator.Generate()["library.v1.Book"]
+	require.Equal(t, true, schema["deprecated"])
+	require.Equal(t, []string{"name", "title", "create_time", "etag", "isbn"}, schema["required"])
+	properties := schema["properties"].(map[string]any) //nolint:forcetypeassert
+	require.Equal(t, map[string]any{"type": jsString}, properties["name"])
+	require.Equal(t, map[string]any{"type": jsString}, properties["title"])
+	require.Equal(t, map[string]any{"type": jsString, "readOnly": true}, properties["create_time"])
+	require.Equal(t, map[string]any{"type": jsString, "writeOnly": true}, properties["etag"])
+	require.Equal(t, map[string]any{"type": jsString, "deprecated": true}, properties["isbn"])
+
+	// Without strict mode, only the fields required by their behavior are required.
+	generator = NewGenerator()
+	err = generator.Add(files[0].Messages().Get(0))
+	require.NoError(t, err)
+	schema = generator.Generate()["library.v1.Book"]
+	require.Equal(t, []string{"title"}, schema["required"])
+
+	// The input variant drops output only fields.
+	generator = NewGenerator(WithInput())
+	err = generator.Add(files[0].Messages().Get(0))
+	require.NoError(t, err)
+	schema = generator.Generate()["library.v1.Book"]
+	require.Equal(t, "library.v1.Book.schema.input.json", schema["$id"])
+	require.Equal(t, []string{"title"}, schema["required"])
+	require.NotContains(t, schema["properties"], "create_time")
+	require.Contains(t, schema["properties"], "etag")
+
+	// The output variant drops input only fields, and required field behaviors.
+	generator = NewGenerator(WithOutput(), WithStrict())
+	err = generator.Add(files[0].Messages().Get(0))
+	require.NoError(t, err)
+	schema = generator.Generate()["library.v1.Book"]
+	require.Equal(t, "library.v1.Book.schema.strict.output.json", schema["$id"])
+	require.Equal(t, []string{"name", "title", "create_time", "isbn"}, schema["required"])
+	require.Contains(t, schema["properties"], "create_time")
+	require.NotContains(t, schema["properties"], "etag")
+	generator = NewGenerator(WithOutput())
+	err = generator.Add(files[0].Messages().Get(0))
+	require.NoError(t, err)
+	schema = generator.Generate()["library.v1.Book"]
+	require.NotContains(t, schema, "required")
+}
+
+func TestPatch(t *testing.T) {
+	t.Parallel()
+	testDescs, err := golden.GetTestDescriptors("../../testdata")
+	require.NoError(t, err)
+	var desc protoreflect.MessageDescriptor
+	for _, testDesc := range testDescs {
+		if testDesc.FullName() == "buf.protoschema.test.v1.Product" {
+			desc = testDesc
+		}
+	}
+	generator := NewGenerator(WithPatch(), WithStrict(), WithBundle())
+	err = generator.Add(desc)
+	require.NoError(t, err)
+	schema := generator.Generate()[desc.FullName()]
+	require.Equal(t, "buf.protoschema.test.v1.Product.schema.strict.patch.bundle.json", schema["$id"])
+	defs, ok := schema["$defs"].(map[string]any)
+	require.True(t, ok)
+	require.Len(t, defs, 2)
+
+	// Nothing is required, but the values are still constrained.
+	for id, def := range defs {
+		require.NotContains(t, def, "required", id)
+	}
+	location, ok := defs["buf.protoschema.test.v1.Product.Location.schema.strict.patch.json"].(map[string]any)
+	require.True(t, ok)
+	require.Equal(t, map[string]any{
+		"type":    jsNumber,
+		"minimum": float64(-90),
+		"maximum": float64(90),
+	}, location["properties"].(map[string]any)["lat"]) //nolint:forcetypeassert
+
+	data, err := json.Marshal(schema)
+	require.NoError(t, err)
+	schemaData, err := jsonschema.UnmarshalJSON(strings.NewReader(string(data)))
+	require.NoError(t, err)
+	compiler := jsonschema.NewCompiler()
+	err = compiler.AddResource("file:///patch.json", schemaData)
+	require.NoError(t, err)
+	compiled, err := compiler.Compile("file:///patch.json")
+	require.NoError(t, err)
+	require.NoError(t, compiled.Validate(map[string]any{}))
+	require.NoError(t, compiled.Validate(map[string]any{"location": map[string]any{"lat": 45.0}}))
+	require.Error(t, compiled.Validate(map[string]any{"location": map[string]any{"lat": 135.0}}))
+	require.Error(t, compiled.Validate(map[string]any{"price": "free"}))
+
+	// CEL rules requiring a field are not translated, but other constraints are.
+	for _, testDesc := range testDescs {
+		if testDesc.FullName() == "buf.protoschema.test.v1.ConstraintTests" {
+			desc = testDesc
+		}
+	}
+	generator = NewGenerator(WithPatch(), WithStrict(), WithBundle())
+	err = generator.Add(desc)
+	require.NoError(t, err)
+	schema = generator.Generate()[desc.FullName()]
+	defs, ok = schema["$defs"].(map[string]any)
+	require.True(t, ok)
+	for id, def := range defs {
+		assertNothingRequired(t, def, id)
+	}
+	celRules, ok := defs["buf.protoschema.test.v1.ConstraintTest.CelRules.schema.strict.patch.json"].(map[string]any)
+	require.True(t, ok)
+	require.Equal(t, []map[string]any{{
+		"if":   map[string]any{"required": []string{"a"}},
+		"then": map[string]any{"properties": map[string]any{"c": map[string]any{"minLength": int64(3)}}},
+	}}, celRules["allOf"])
+	require.Len(t, celRules["x-cel"], 2)
+
+	// Setting a without b is a valid partial update.
+	data, err = json.Marshal(schema)
+	require.NoError(t, err)
+	schemaData, err = jsonschema.UnmarshalJSON(strings.NewReader(string(data)))
+	require.NoError(t, err)
+	compiler = jsonschema.NewCompiler()
+	err = compiler.AddResource("file:///patch.json", schemaData)
+	require.NoError(t, err)
+	compiled, err = compiler.Compile("file:///patch.json#/$defs/buf.protoschema.test.v1.ConstraintTest.CelRules.schema.strict.patch.json")
+	require.NoError(t, err)
+	require.NoError(t, compiled.Validate(map[string]any{"a": "x"}))
+	require.Error(t, compiled.Validate(map[string]any{"a": "x", "c": "ab"}))
+}
+
+// assertNothingRequired asserts that the given schema does not require any property, except in
+// conditions (if, not and the options of a oneOf that also accepts none of them) and the
+// encoding of Any values.
+func assertNothingRequired(t *testing.T, schema any, path string) {
+	t.Helper()
+	switch schema := schema.(type) {
+	case map[string]any:
+		if properties, ok := schema["properties"].(map[string]any); ok && properties["@type"] != nil {
+			return // An Any value always requires its type URL.
+		}
+		require.NotContains(t, schema, "required", path)
+		require.NotContains(t, schema, "dependentRequired", path)
+		for key, value := range schema {
+			switch key {
+			case "if", "not", "oneOf":
+				continue // Conditions may test the presence of a field.
+			}
+			assertNothingRequired(t, value, path+"/"+key)
+		}
+	case []map[string]any:
+		for i, value := range schema {
+			assertNothingRequired(t, value, path+"/"+strconv.Itoa(i))
+		}
+	case []any:
+		for i, value := range schema {
+			assertNothingRequired(t, value, path+"/"+strconv.Itoa(i))
+		}
+	}
+}
+
+func TestCELTranslation(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		// field is the field the rule is attached to, or empty for a message rule.
+		field      string
+		expression string
+		// want is the translated schema, or nil if the rule falls back to an x-cel annotation.
+		want map[string]any
+		// samples are messages in JSON, used to check the schema accepts every message the
+		// expression accepts, and that the translation rejects the messages marked as rejected.
+		samples []celSample
+	}{
+		// Comparisons.
+		{
+			name: "int32 less", field: "int32_value", expression: "this < 10",
+			want:    map[string]any{"exclusiveMaximum": int64(10)},
+			samples: []celSample{{json: `{"int32_value": 9}`}, {json: `{"int32_value": 10}`, rejected: true}},
+		},
+		{
+			name: "int32 less equals", field: "int32_value", expression: "this <= 10",
+			want:    map[string]any{"maximum": int64(10)},
+			samples: []celSample{{json: `{"int32_value": 10}`}, {json: `{"int32_value": 11}`, rejected: true}},
+		},
+		{
+			name: "int32 greater", field: "int32_value", expression: "this > -1",
+			want:    map[string]any{"exclusiveMinimum": int64(-1)},
+			samples: []celSample{{json: `{"int32_value": 0}`}, {json: `{"int32_value": -1}`, rejected: true}},
+		},
+		{
+			name: "int32 greater equals", field: "int32_value", expression: "this >= 1",
+			want:    map[string]any{"minimum": int64(1)},
+			samples: []celSample{{json: `{"int32_value": 1}`}, {json: `{"int32_value": 0}`, rejected: true}},
+		},
+		{
+			name: "int32 equals", field: "int32_value", expression: "this == 5",
+			want:    map[string]any{"enum": []any{int64(5)}},
+			samples: []celSample{{json: `{"int32_value": 5}`}, {json: `{"int32_value": 4}`, rejected: true}},
+		},
+		{
+			name: "int32 not equals", field: "int32_value", expression: "this != 5",
+			want:    map[string]any{"not": map[string]any{"enum": []any{int64(5)}}},
+			samples: []celSample{{json: `{"int32_value": 4}`}, {json: `{"int32_value": 5}`, rejected: true}},
+		},
+		{
+			name: "int32 flipped", field: "int32_value", expression: "10 > this",
+			want:    map[string]any{"exclusiveMaximum": int64(10)},
+			samples: []celSample{{json: `{"int32_value": 9}`}, {json: `{"int32_value": 10}`, rejected: true}},
+		},
+		{
+			name: "uint64 greater", field: "uint64_value", expression: "this > 0u",
+			want:    map[string]any{"exclusiveMinimum": uint64(0)},
+			samples: []celSample{{json: `{"uint64_value": 1}`}, {json: `{"uint64_value": 0}`, rejected: true}},
+		},
+		{
+			name: "double greater equals", field: "double_value", expression: "this >= 1.5",
+			want:    map[string]any{"minimum": 1.5},
+			samples: []celSample{{json: `{"double_value": 1.5}`}, {json: `{"double_value": 1.25}`, rejected: true}},
+		},
+		{
+			name: "string equals", field: "string_value", expression: `this == "a"`,
+			want:    map[string]any{"enum": []any{"a"}},
+			samples: []celSample{{json: `{"string_value": "a"}`}, {json: `{"string_value": "b"}`, rejected: true}},
+		},
+		{
+			name: "string not equals", field: "string_value", expression: `this != ""`,
+			want:    map[string]any{"not": map[string]any{"enum": []any{""}}},
+			samples: []celSample{{json: `{"string_value": "a"}`}, {json: `{"string_value": ""}`, rejected: true}},
+		},
+		{
+			name: "bool equals", field: "bool_value", expression: "this == true",
+			want:    map[string]any{"enum": []any{true}},
+			samples: []celSample{{json: `{"bool_value": true}`}, {json: `{"bool_value": false}`, rejected: true}},
+		},
+		{
+			name: "string ordering", field: "string_value", expression: `this < "b"`,
+			samples: []celSample{{json: `{"string_value": "a"}`}},
+		},
+		{
+			name: "bytes equals", field: "bytes_value", expression: `this == b"a"`,
+			samples: []celSample{{json: `{"bytes_value": "YQ=="}`}},
+		},
+		{
+			name: "enum equals", field: "enum_value", expression: "this == 1",
+			samples: []celSample{{json: `{"enum_value": "STATUS_OPEN"}`}},
+		},
+		{
+			name: "mismatched kind", field: "int32_value", expression: `this == "a"`,
+		},
+		// Sizes.
+		{
+			name: "string size less equals", field: "string_value", expression: "size(this) <= 3",
+			want:    map[string]any{"maxLength": int64(3)},
+			samples: []celSample{{json: `{"string_value": "äöü"}`}, {json: `{"string_value": "abcd"}`, rejected: true}},
+		},
+		{
+			name: "string size greater", field: "string_value", expression: "this.size() > 2",
+			want:    map[string]any{"minLength": int64(3)},
+			samples: []celSample{{json: `{"string_value": "abc"}`}, {json: `{"string_value": "ab"}`, rejected: true}},
+		},
+		{
+			name: "string size equals", field: "string_value", expression: "size(this) == 2",
+			want:    map[string]any{"minLength": int64(2), "maxLength": int64(2)},
+			samples: []celSample{{json: `{"string_value": "ab"}`}, {json: `{"string_value": "a"}`, rejected: true}},
+		},
+		{
+			name: "string size less", field: "string_value", expression: "size(this) < 3",
+			want:    map[string]any{"maxLength": int64(2)},
+			samples: []celSample{{json: `{"string_value": "ab"}`}, {json: `{"string_value": "abc"}`, rejected: true}},
+		},
+		{
+			name: "string size less than zero", field: "string_value", expression: "size(this) < 0",
+		},
+		{
+			name: "string size not equals", field: "string_value", expression: "size(this) != 2",
+			samples: []celSample{{json: `{"string_value": "a"}`}},
+		},
+		{
+			// The JSON schema would count the base64 characters.
+			name: "bytes size", field: "bytes_value", expression: "size(this) <= 2",
+			samples: []celSample{{json: `{"bytes_value": "YWI="}`}},
+		},
+		{
+			name: "list size", field: "list_value", expression: "size(this) >= 1",
+			want:    map[string]any{"minItems": int64(1)},
+			samples: []celSample{{json: `{"list_value": ["a"]}`}, {json: `{"list_value": []}`, rejected: true}},
+		},
+		{
+			name: "map size", field: "map_value", expression: "size(this) <= 1",
+			want:    map[string]any{"maxProperties": int64(1)},
+			samples: []celSample{{json: `{"map_value": {"a": "b"}}`}, {json: `{"map_value": {"a": "b", "c": "d"}}`, rejected: true}},
+		},
+		// In.
+		{
+			name: "string in", field: "string_value", expression: `this in ["a", "b"]`,
+			want:    map[string]any{"enum": []any{"a", "b"}},
+			samples: []celSample{{json: `{"string_value": "b"}`}, {json: `{"string_value": "c"}`, rejected: true}},
+		},
+		{
+			name: "string not in", field: "string_value", expression: `!(this in ["a", "b"])`,
+			want:    map[string]any{"not": map[string]any{"enum": []any{"a", "b"}}},
+			samples: []celSample{{json: `{"string_value": "c"}`}, {json: `{"string_value": "a"}`, rejected: true}},
+		},
+		{
+			name: "int32 in", field: "int32_value", expression: "this in [1, 2]",
+			want:    map[string]any{"enum": []any{int64(1), int64(2)}},
+			samples: []celSample{{json: `{"int32_value": 2}`}, {json: `{"int32_value": 3}`, rejected: true}},
+		},
+		{
+			name: "enum in", field: "enum_value", expression: "this in [1]",
+			samples: []celSample{{json: `{"enum_value": "STATUS_OPEN"}`}},
+		},
+		{
+			name: "in non-constant", field: "string_value", expression: `this in [this]`,
+			samples: []celSample{{json: `{"string_value": "a"}`}},
+		},
+		// Matches.
+		{
+			name: "matches member", field: "string_value", expression: `this.matches("^[a-z]+$")`,
+			want:    map[string]any{"pattern": "^[a-z]+$"},
+			samples: []celSample{{json: `{"string_value": "abc"}`}, {json: `{"string_value": "ABC"}`, rejected: true}},
+		},
+		{
+			name: "matches global", field: "string_value", expression: `matches(this, "^a")`,
+			want:    map[string]any{"pattern": "^a"},
+			samples: []celSample{{json: `{"string_value": "ab"}`}, {json: `{"string_value": "ba"}`, rejected: true}},
+		},
+		{
+			name: "conditional message", field: "string_value", expression: `this.matches("^a") ? "" : "must start with a"`,
+			want:    map[string]any{"pattern": "^a"},
+			samples: []celSample{{json: `{"string_value": "ab"}`}, {json: `{"string_value": "ba"}`, rejected: true}},
+		},
+		// Message rules.
+		{
+			name: "message comparison", expression: "this.int32_value > 0",
+			want:    map[string]any{"properties": map[string]any{"int32_value": map[string]any{"exclusiveMinimum": int64(0)}}},
+			samples: []celSample{{json: `{"int32_value": 1}`}, {json: `{"int32_value": 0}`, rejected: true}},
+		},
+		{
+			name: "conjunction", expression: `this.int32_value > 0 && this.string_value != ""`,
+			want: map[string]any{"allOf": []map[string]any{
+				{"properties": map[string]any{"int32_value": map[string]any{"exclusiveMinimum": int64(0)}}},
+				{"properties": map[string]any{"string_value": map[string]any{"not": map[string]any{"enum": []any{""}}}}},
+			}},
+			samples: []celSample{{json: `{"int32_value": 1, "string_value": "a"}`}, {json: `{"int32_value": 1, "string_value": ""}`, rejected: true}},
+		},
+		{
+			name: "has", expression: "has(this.a)",
+			want:    map[string]any{"required": []string{"a"}},
+			samples: []celSample{{json: `{"a": ""}`}, {json: `{}`, rejected: true}},
+		},
+		{
+			name: "implies has", expression: "!has(this.a) || has(this.b)",
+			want:    map[string]any{"dependentRequired": map[string][]string{"a": {"b"}}},
+			samples: []celSample{{json: `{}`}, {json: `{"b": ""}`}, {json: `{"a": "", "b": ""}`}, {json: `{"a": ""}`, rejected: true}},
+		},
+		{
+			name: "implies has flipped", expression: "has(this.b) || !has(this.a)",
+			want:    map[string]any{"dependentRequired": map[string][]string{"a": {"b"}}},
+			samples: []celSample{{json: `{"a": "", "b": ""}`}, {json: `{"a": ""}`, rejected: true}},
+		},
+		{
+			// Without presence, a set field must not have the zero value.
+			name: "implies has implicit presence", expression: "!has(this.a) || has(this.c)",
+			want: map[string]any{
+				"if":   map[string]any{"required": []string{"a"}},
+				"then": map[string]any{"required": []string{"c"}, "properties": map[string]any{"c": map[string]any{"not": map[string]any{"enum": []any{""}}}}},
+			},
+			samples: []celSample{{json: `{"a": "", "c": "x"}`}, {json: `{"a": "", "c": ""}`, rejected: true}},
+		},
+		{
+			name: "implies size", expression: "!has(this.a) || size(this.c) >= 3",
+			want: map[string]any{
+				"if":   map[string]any{"required": []string{"a"}},
+				"then": map[string]any{"properties": map[string]any{"c": map[string]any{"minLength": int64(3)}}},
+			},
+			samples: []celSample{{json: `{"c": "x"}`}, {json: `{"a": "", "c": "xyz"}`}, {json: `{"a": "", "c": "x"}`, rejected: true}},
+		},
+		{
+			name: "not has", expression: "!has(this.a)",
+			want:    map[string]any{"not": map[string]any{"required": []string{"a"}}},
+			samples: []celSample{{json: `{}`}, {json: `{"a": ""}`, rejected: true}},
+		},
+		// Fallbacks.
+		{
+			name: "field comparison", expression: "this.int32_value <= this.uint64_value",
+			samples: []celSample{{json: `{"int32_value": 1, "uint64_value": 2}`}},
+		},
+		{
+			name: "disjunction", expression: `this.int32_value > 0 || this.string_value != ""`,
+			samples: []celSample{{json: `{"string_value": "a"}`}},
+		},
+		{
+			name: "arithmetic", field: "int32_value", expression: "this * 2 > 3",
+			samples: []celSample{{json: `{"int32_value": 2}`}},
+		},
+		{
+			name: "negated comparison", field: "int32_value", expression: "!(this > 3)",
+			samples: []celSample{{json: `{"int32_value": 2}`}},
+		},
+		{
+			name: "partial conjunction", field: "int32_value", expression: "this > 0 && this * 2 > 3",
+			samples: []celSample{{json: `{"int32_value": 2}`}},
+		},
+	}
+	validator, err := protovalidate.New()
+	require.NoError(t, err)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			desc := compileCELTest(t, test.field, test.expression)
+			generator := NewGenerator(WithStrict())
+			err := generator.Add(desc)
+			require.NoError(t, err)
+			schema := generator.Generate()[desc.FullName()]
+			target := schema
+			if test.field != "" {
+				target = schema["properties"].(map[string]any)[test.field].(map[string]any) //nolint:forcetypeassert
+			}
+			if test.want == nil {
+				require.NotContains(t, target, "allOf")
+				require.Equal(t, []map[string]any{{"id": "test", "expression": test.expression}}, target["x-cel"])
+			} else {
+				require.NotContains(t, target, "x-cel")
+				require.Equal(t, []map[string]any{test.want}, flattenAllOf(target["allOf"]))
+			}
+
+			// Only check the rule, not the fields required by strict mode.
+			delete(schema, "required")
+			data, err := json.Marshal(schema)
+			require.NoError(t, err)
+			schemaData, err := jsonschema.UnmarshalJSON(strings.NewReader(string(data)))
+			require.NoError(t, err)
+			compiler := jsonschema.NewCompiler()
+			err = compiler.AddResource("file:///cel.json", schemaData)
+			require.NoError(t, err)
+			compiled, err := compiler.Compile("file:///cel.json")
+			require.NoError(t, err)
+			for _, sample := range test.samples {
+				msg := dynamicpb.NewMessage(desc)
+				err := protojson.Unmarshal([]byte(sample.json), msg)
+				require.NoError(t, err, sample.json)
+				validationErr := validator.Validate(msg)
+				require.Equal(t, sample.rejected, validationErr != nil, "%s: %v", sample.json, validationErr)
+				instance, err := jsonschema.UnmarshalJSON(strings.NewReader(sample.json))
+				require.NoError(t, err)
+				schemaErr := compiled.Validate(instance)
+				if validationErr == nil {
+					// A translation must never reject a value the expression accepts.
+					require.NoError(t, schemaErr, sample.json)
+				} else if test.want != nil {
+					require.Error(t, schemaErr, sample.json)
+				}
+			}
+		})
+	}
+}
+
+// celSample is a message in JSON, and whether protovalidate rejects it.
+type celSample struct {
+	json     string
+	rejected bool
+}
+
+// flattenAllOf returns the translated schemas in the given allOf, with a single conjunction
+// regrouped, as the translator flattens the conjunctions of a rule.
+func flattenAllOf(allOf any) []map[string]any {
+	schemas, _ := allOf.([]map[string]any)
+	if len(schemas) > 1 {
+		return []map[string]any{{"allOf": schemas}}
+	}
+	return schemas
+}
+
+// compileCELTest compiles a message with the given CEL rule, on the given field or on the
+// message if field is empty.
+func compileCELTest(t *testing.T, field string, expression string) protoreflect.MessageDescriptor {
+	t.Helper()
+	rule := fmt.Sprintf(`{id: "test", expression: %s}`, strconv.Quote(expression))
+	fieldOptions := make(map[string]string)
+	messageOption := ""
+	if field == "" {
+		messageOption = "option (buf.validate.message).cel = " + rule + ";"
+	} else {
+		fieldOptions[field] = " [(buf.validate.field).cel = " + rule + "]"
+	}
+	source := fmt.Sprintf(`
+		syntax = "proto3";
+		package cel.v1;
+		import "buf/validate/validate.proto";
+		enum Status {
+		  STATUS_UNSPECIFIED = 0;
+		  STATUS_OPEN = 1;
+		}
+		message Values {
+		  %s
+		  int32 int32_value = 1%s;
+		  uint64 uint64_value = 2%s;
+		  double double_value = 3%s;
+		  string string_value = 4%s;
+		  bytes bytes_value = 5%s;
+		  bool bool_value = 6%s;
+		  repeated string list_value = 7%s;
+		  map<string, string> map_value = 8%s;
+		  Status enum_value = 9%s;
+		  optional string a = 10;
+		  optional string b = 11;
+		  string c = 12;
+		}
+	`, messageOption,
+		fieldOptions["int32_value"], fieldOptions["uint64_value"], fieldOptions["double_value"],
+		fieldOptions["string_value"], fieldOptions["bytes_value"], fieldOptions["bool_value"],
+		fieldOptions["list_value"], fieldOptions["map_value"], fieldOptions["enum_value"])
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.CompositeResolver{
+			protocompile.WithStandardImports(&protocompile.SourceResolver{
+				Accessor: protocompile.SourceAccessorFromMap(map[string]string{"cel/v1/values.proto": source}),
+			}),
+			protocompile.ResolverFunc(func(path string) (protocompile.SearchResult, error) {
+				desc, err := protoregistry.GlobalFiles.FindFileByPath(path)
+				return protocompile.SearchResult{Desc: desc}, err
+			}),
+		},
+	}
+	files, err := compiler.Compile(t.Context(), "cel/v1/values.proto")
+	require.NoError(t, err)
+	return files[0].Messages().ByName("Values")
+}
+
 func TestConstraints(t *testing.T) {
 	t.Parallel()
 	schemaPath := filepath.FromSlash("../../testdata/jsonschema/buf.protoschema.test.v1.ConstraintTests.schema.json")
@@ -90,6 +964,86 @@ func TestConstraints(t *testing.T) {
 	assertValidation(t, bundledSchema, jsonData, expectedBundledPath)
 }
 
+func TestConstraintsProtovalidate(t *testing.T) {
+	t.Parallel()
+	// The fields whose rules are translated exactly, so the JSON schema and protovalidate
+	// must agree on whether a test case is valid.
+	exactFields := map[protoreflect.Name]struct{}{
+		"unique_list": {},
+		"pairs_map":   {},
+	}
+	schemaPath := filepath.FromSlash("../../testdata/jsonschema/buf.protoschema.test.v1.ConstraintTest.schema.json")
+	testPath := filepath.FromSlash("../../testdata/jsonschema-doc/test.ConstraintTests.yaml")
+	schema, err := jsonschema.NewCompiler().Compile(schemaPath)
+	require.NoError(t, err)
+	testDescs, err := golden.GetTestDescriptors("../../testdata")
+	require.NoError(t, err)
+	var desc protoreflect.MessageDescriptor
+	for _, testDesc := range testDescs {
+		if testDesc.FullName() == "buf.protoschema.test.v1.ConstraintTest" {
+			desc = testDesc
+		}
+	}
+	require.NotNil(t, desc)
+	validator, err := protovalidate.New()
+	require.NoError(t, err)
+
+	yamlData, err := os.ReadFile(testPath)
+	require.NoError(t, err)
+	var testData struct {
+		TestCases []map[string]any `yaml:"test_cases"`
+	}
+	err = yaml.Unmarshal(yamlData, &testData)
+	require.NoError(t, err)
+
+	var checked int
+	for i, testCase := range testData.TestCases {
+		fields := make(map[protoreflect.Name]struct{}, len(testCase))
+		for key := range testCase {
+			field := desc.Fields().ByJSONName(key)
+			if field == nil {
+				field = desc.Fields().ByName(protoreflect.Name(key))
+			}
+			require.NotNil(t, field, "test case %d: unknown field %q", i, key)
+			fields[field.Name()] = struct{}{}
+		}
+		if !isSubset(fields, exactFields) {
+			continue
+		}
+		checked++
+
+		jsonData, err := json.Marshal(testCase)
+		require.NoError(t, err)
+		msg := dynamicpb.NewMessage(desc)
+		err = protojson.Unmarshal(jsonData, msg)
+		require.NoError(t, err, "test case %d", i)
+		// Only consider violations of the fields in the test case.
+		var violations []*protovalidate.Violation
+		if err := validator.Validate(msg); err != nil {
+			var validationErr *protovalidate.ValidationError
+			require.ErrorAs(t, err, &validationErr)
+			for _, violation := range validationErr.Violations {
+				if _, ok := fields[violation.FieldDescriptor.Name()]; ok {
+					violations = append(violations, violation)
+				}
+			}
+		}
+		schemaErr := schema.Validate(testCase)
+		require.Equal(t, len(violations) == 0, schemaErr == nil,
+			"test case %d: protovalidate violations %v, schema error %v", i, violations, schemaErr)
+	}
+	require.NotZero(t, checked)
+}
+
+func isSubset[T comparable](set map[T]struct{}, superset map[T]struct{}) bool {
+	for key := range set {
+		if _, ok := superset[key]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
 func assertValidation(t *testing.T, schema *jsonschema.Schema, jsonData map[string]any, expectedPath string) {
 	t.Helper()
 	expectedData, err := os.ReadFile(expectedPath)
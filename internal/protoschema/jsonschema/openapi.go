@@ -0,0 +1,187 @@
+// Copyright 2024-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonschema
+
+import (
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const componentsPrefix = "#/components/schemas/"
+
+// GenerateOpenAPI returns an OpenAPI 3.1 document for each package of the added message and
+// service descriptors, keyed by file name.
+//
+// Each document contains the schemas of the added messages of the package, and all their
+// dependencies, under components/schemas. The schemas are keyed by the full name of the message,
+// and all references are rewritten to point to these components. The methods of the added
+// services are included under paths.
+func (p *Generator) GenerateOpenAPI() (map[string]map[string]any, error) {
+	for _, payload := range p.anyPayloads {
+		p.generateAnyPayloadReferences(payload)
+	}
+	// Map the IDs of the schemas to their names, to rewrite references.
+	names := make(map[string]protoreflect.FullName, len(p.schema))
+	for name, entry := range p.schema {
+		names[strings.TrimPrefix(entry.id, defsPrefix)] = name
+	}
+
+	// Collect the schemas of each package.
+	packages := make(map[protoreflect.FullName]map[protoreflect.FullName]*msgSchema)
+	for name, entry := range p.schema {
+		if !entry.added {
+			continue
+		}
+		pkg := entry.desc.ParentFile().Package()
+		if packages[pkg] == nil {
+			packages[pkg] = make(map[protoreflect.FullName]*msgSchema)
+		}
+		p.collectReferences(name, packages[pkg])
+	}
+	paths := make(map[protoreflect.FullName]map[string]any)
+	for _, service := range p.services {
+		pkg := service.ParentFile().Package()
+		if packages[pkg] == nil {
+			packages[pkg] = make(map[protoreflect.FullName]*msgSchema)
+		}
+		if paths[pkg] == nil {
+			paths[pkg] = make(map[string]any)
+		}
+		for i := range service.Methods().Len() {
+			method := service.Methods().Get(i)
+			p.collectReferences(method.Input().FullName(), packages[pkg])
+			p.collectReferences(method.Output().FullName(), packages[pkg])
+		}
+		if err := p.generatePaths(service, names, paths[pkg]); err != nil {
+			return nil, fmt.Errorf("failed to generate paths for %q: %w", service.FullName(), err)
+		}
+	}
+
+	result := make(map[string]map[string]any, len(packages))
+	for pkg, entries := range packages {
+		schemas := make(map[string]any, len(entries))
+		for name, entry := range entries {
+			schema, _ := rewriteRefs(entry.schema, name, names).(map[string]any)
+			delete(schema, "$schema")
+			delete(schema, "$id")
+			schemas[string(name)] = schema
+		}
+		document := map[string]any{
+			"openapi":           "3.1.0",
+			"jsonSchemaDialect": "https://json-schema.org/draft/2020-12/schema",
+			"info": map[string]any{
+				"title":   string(pkg),
+				"version": "0.0.0",
+			},
+			"components": map[string]any{
+				"schemas": schemas,
+			},
+		}
+		if len(paths[pkg]) > 0 {
+			document["paths"] = paths[pkg]
+		}
+		result[p.getOpenAPIID(pkg)] = document
+	}
+	return result, nil
+}
+
+// getOpenAPIID returns the file name of the OpenAPI document for the given package.
+func (p *Generator) getOpenAPIID(pkg protoreflect.FullName) string {
+	result := string(pkg)
+	if p.useJSONNames {
+		result += ".jsonschema"
+	} else {
+		result += ".schema"
+	}
+	if p.strict {
+		result += ".strict"
+	}
+	if p.variant != variantDefault {
+		result += "." + string(p.variant)
+	}
+	return strings.TrimPrefix(result+".openapi.json", ".")
+}
+
+// collectReferences recursively collects the schema with the given name and all the schemas
+// it references.
+func (p *Generator) collectReferences(name protoreflect.FullName, entries map[protoreflect.FullName]*msgSchema) {
+	entry, ok := p.schema[name]
+	if !ok {
+		return // Not found.
+	}
+	if _, ok := entries[name]; ok {
+		return // Already added.
+	}
+	entries[name] = entry
+	for ref := range entry.refs {
+		p.collectReferences(ref, entries)
+	}
+}
+
+// rewriteRefs returns a copy of the given schema value with all references rewritten to point
+// to the components of an OpenAPI document.
+//
+// The self name is the name of the schema containing the value, used for local references.
+func rewriteRefs(value any, self protoreflect.FullName, names map[string]protoreflect.FullName) any {
+	switch value := value.(type) {
+	case map[string]any:
+		result := make(map[string]any, len(value))
+		for key, item := range value {
+			if ref, ok := item.(string); ok && key == "$ref" {
+				result[key] = rewriteRef(ref, self, names)
+			} else {
+				result[key] = rewriteRefs(item, self, names)
+			}
+		}
+		return result
+	case []map[string]any:
+		result := make([]map[string]any, len(value))
+		for i, item := range value {
+			result[i], _ = rewriteRefs(item, self, names).(map[string]any)
+		}
+		return result
+	case []any:
+		result := make([]any, len(value))
+		for i, item := range value {
+			result[i] = rewriteRefs(item, self, names)
+		}
+		return result
+	default:
+		return value
+	}
+}
+
+// rewriteRef rewrites a single reference to point to the components of an OpenAPI document.
+func rewriteRef(ref string, self protoreflect.FullName, names map[string]protoreflect.FullName) string {
+	id, fragment, _ := strings.Cut(ref, "#")
+	if id == "" {
+		// A local reference, either to the same schema or to a bundled definition.
+		rest, ok := strings.CutPrefix(fragment, "/$defs/")
+		if !ok {
+			return componentsPrefix + string(self) + fragment
+		}
+		id, fragment, _ = strings.Cut(rest, "/")
+		if fragment != "" {
+			fragment = "/" + fragment
+		}
+	}
+	name, ok := names[id]
+	if !ok {
+		return ref // Not a generated schema.
+	}
+	return componentsPrefix + string(name) + fragment
+}
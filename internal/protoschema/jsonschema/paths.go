@@ -0,0 +1,298 @@
+// Copyright 2024-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package jsonschema
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/bufbuild/protoschema-plugins/internal/protoschema/annotations"
+	apiannotations "google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// pathVariablePattern matches the variables of a google.api.http path template, e.g.
+// "{name=shelves/*}", capturing the field path.
+var pathVariablePattern = regexp.MustCompile(`\{([^}=]+)(=[^}]*)?\}`)
+
+// AddService adds a service descriptor to the generator.
+//
+// The request and response messages of the methods are generated, and the methods are included
+// as paths in the documents returned by GenerateOpenAPI. Methods with a google.api.http rule use
+// the paths of the rule, and other methods use the Connect path (e.g. "POST /foo.v1.FooService/Bar").
+// Streaming methods are skipped.
+func (p *Generator) AddService(desc protoreflect.ServiceDescriptor) error {
+	for i := range desc.Methods().Len() {
+		method := desc.Methods().Get(i)
+		if method.IsStreamingClient() || method.IsStreamingServer() {
+			continue
+		}
+		if _, err := p.generate(method.Input()); err != nil {
+			return fmt.Errorf("failed to generate schema for %q: %w", method.Input().FullName(), err)
+		}
+		if _, err := p.generate(method.Output()); err != nil {
+			return fmt.Errorf("failed to generate schema for %q: %w", method.Output().FullName(), err)
+		}
+	}
+	p.services = append(p.services, desc)
+	return nil
+}
+
+// generatePaths adds the OpenAPI paths of the given service to the given paths object.
+func (p *Generator) generatePaths(desc protoreflect.ServiceDescriptor, names map[string]protoreflect.FullName, paths map[string]any) error {
+	for i := range desc.Methods().Len() {
+		method := desc.Methods().Get(i)
+		if method.IsStreamingClient() || method.IsStreamingServer() {
+			continue
+		}
+		rule := annotations.GetHTTPRule(method)
+		if rule == nil {
+			// Connect unary requests are always a POST of the request message.
+			rule = &apiannotations.HttpRule{
+				Pattern: &apiannotations.HttpRule_Post{Post: "/" + string(desc.FullName()) + "/" + string(method.Name())},
+				Body:    "*",
+			}
+		}
+		operationID := string(method.FullName())
+		if err := p.generateOperation(method, rule, operationID, names, paths); err != nil {
+			return err
+		}
+		for j, binding := range rule.GetAdditionalBindings() {
+			if err := p.generateOperation(method, binding, operationID+"_"+strconv.Itoa(j+1), names, paths); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// generateOperation adds the OpenAPI operation for a single HTTP rule of a method to the given
+// paths object.
+func (p *Generator) generateOperation(
+	method protoreflect.MethodDescriptor,
+	rule *apiannotations.HttpRule,
+	operationID string,
+	names map[string]protoreflect.FullName,
+	paths map[string]any,
+) error {
+	httpMethod, template := getHTTPPattern(rule)
+	if template == "" {
+		return fmt.Errorf("method %q has an http rule without a pattern", method.FullName())
+	}
+	switch httpMethod {
+	case "get", "put", "post", "delete", "options", "head", "patch", "trace":
+	default:
+		return fmt.Errorf("method %q has an unsupported http method %q", method.FullName(), httpMethod)
+	}
+
+	operation := map[string]any{
+		"operationId": operationID,
+		"tags":        []string{string(method.Parent().Name())},
+	}
+	description := make(map[string]any)
+	p.setDescription(method, description)
+	if title, ok := description["title"]; ok {
+		operation["summary"] = title
+	}
+	if text, ok := description["description"]; ok {
+		operation["description"] = text
+	}
+
+	// Path parameters.
+	var parameters []map[string]any
+	bound := make(map[string]struct{})
+	for _, match := range pathVariablePattern.FindAllStringSubmatch(template, -1) {
+		fieldPath := strings.TrimSpace(match[1])
+		schema, err := p.getFieldPathSchema(method.Input(), fieldPath, names)
+		if err != nil {
+			return fmt.Errorf("method %q has an invalid path variable: %w", method.FullName(), err)
+		}
+		parameters = append(parameters, map[string]any{
+			"name":     fieldPath,
+			"in":       "path",
+			"required": true,
+			"schema":   schema,
+		})
+		bound[strings.SplitN(fieldPath, ".", 2)[0]] = struct{}{}
+	}
+	path := pathVariablePattern.ReplaceAllString(template, "{$1}")
+
+	// Request body and query parameters.
+	switch body := rule.GetBody(); body {
+	case "*":
+		operation["requestBody"] = map[string]any{
+			"required": true,
+			"content":  jsonContent(map[string]any{"$ref": componentsPrefix + string(method.Input().FullName())}),
+		}
+	case "":
+		parameters = append(parameters, p.generateQueryParameters(method.Input(), bound, names)...)
+	default:
+		schema, err := p.getFieldPathSchema(method.Input(), body, names)
+		if err != nil {
+			return fmt.Errorf("method %q has an invalid body: %w", method.FullName(), err)
+		}
+		operation["requestBody"] = map[string]any{
+			"required": true,
+			"content":  jsonContent(schema),
+		}
+		bound[body] = struct{}{}
+		parameters = append(parameters, p.generateQueryParameters(method.Input(), bound, names)...)
+	}
+	if len(parameters) > 0 {
+		operation["parameters"] = parameters
+	}
+
+	// Response body.
+	responseSchema := map[string]any{"$ref": componentsPrefix + string(method.Output().FullName())}
+	if responseBody := rule.GetResponseBody(); responseBody != "" {
+		var err error
+		if responseSchema, err = p.getFieldPathSchema(method.Output(), responseBody, names); err != nil {
+			return fmt.Errorf("method %q has an invalid response body: %w", method.FullName(), err)
+		}
+	}
+	operation["responses"] = map[string]any{
+		"200": map[string]any{
+			"description": "OK",
+			"content":     jsonContent(responseSchema),
+		},
+	}
+
+	pathItem, ok := paths[path].(map[string]any)
+	if !ok {
+		pathItem = make(map[string]any)
+		paths[path] = pathItem
+	}
+	if _, ok := pathItem[httpMethod]; ok {
+		return fmt.Errorf("method %q duplicates the operation %s %s", method.FullName(), strings.ToUpper(httpMethod), path)
+	}
+	pathItem[httpMethod] = operation
+	return nil
+}
+
+// generateQueryParameters returns the query parameters for the fields of the given message
+// that are not bound to the path or body.
+//
+// Only fields with a scalar (or well-known type) JSON representation are included.
+func (p *Generator) generateQueryParameters(desc protoreflect.MessageDescriptor, bound map[string]struct{}, names map[string]protoreflect.FullName) []map[string]any {
+	var result []map[string]any
+	for i := range desc.Fields().Len() {
+		field := desc.Fields().Get(i)
+		if _, ok := bound[string(field.Name())]; ok {
+			continue
+		}
+		if field.IsMap() {
+			continue
+		}
+		if field.Message() != nil && !isQueryMessage(p, field.Message()) {
+			continue
+		}
+		schema, ok := p.getPropertySchema(field, names)
+		if !ok {
+			continue // Ignored field.
+		}
+		result = append(result, map[string]any{
+			"name":   p.getPropertyName(field),
+			"in":     "query",
+			"schema": schema,
+		})
+	}
+	return result
+}
+
+// isQueryMessage returns true if the given message has a scalar JSON representation, so it
+// can be used as a query parameter.
+func isQueryMessage(p *Generator, desc protoreflect.MessageDescriptor) bool {
+	switch desc.FullName() {
+	case anyFullName, "google.protobuf.Struct", "google.protobuf.Value", "google.protobuf.ListValue":
+		return false
+	}
+	_, ok := p.custom[desc.FullName()]
+	return ok
+}
+
+// getFieldPathSchema returns the schema of the field at the given path (e.g. "book.name") in
+// the given message.
+func (p *Generator) getFieldPathSchema(desc protoreflect.MessageDescriptor, fieldPath string, names map[string]protoreflect.FullName) (map[string]any, error) {
+	var field protoreflect.FieldDescriptor
+	for part := range strings.SplitSeq(fieldPath, ".") {
+		if field != nil {
+			if field.Message() == nil || field.IsList() || field.IsMap() {
+				return nil, fmt.Errorf("field %q is not a message", field.FullName())
+			}
+			desc = field.Message()
+		}
+		if field = desc.Fields().ByName(protoreflect.Name(part)); field == nil {
+			return nil, fmt.Errorf("field %q not found in %q", part, desc.FullName())
+		}
+	}
+	schema, ok := p.getPropertySchema(field, names)
+	if !ok {
+		return nil, fmt.Errorf("field %q is ignored", field.FullName())
+	}
+	return schema, nil
+}
+
+// getPropertySchema returns the schema generated for the given field, with references
+// rewritten to the components of an OpenAPI document.
+func (p *Generator) getPropertySchema(field protoreflect.FieldDescriptor, names map[string]protoreflect.FullName) (map[string]any, bool) {
+	entry, ok := p.schema[field.ContainingMessage().FullName()]
+	if !ok {
+		return nil, false
+	}
+	properties, _ := entry.schema["properties"].(map[string]any)
+	schema, ok := properties[p.getPropertyName(field)].(map[string]any)
+	if !ok {
+		return nil, false
+	}
+	result, _ := rewriteRefs(schema, field.ContainingMessage().FullName(), names).(map[string]any)
+	return result, true
+}
+
+// getPropertyName returns the primary name of the given field in the generated schema.
+func (p *Generator) getPropertyName(field protoreflect.FieldDescriptor) string {
+	if p.useJSONNames {
+		return field.JSONName()
+	}
+	return string(field.Name())
+}
+
+// getHTTPPattern returns the lower case HTTP method and path template of the given rule.
+func getHTTPPattern(rule *apiannotations.HttpRule) (string, string) {
+	switch pattern := rule.GetPattern().(type) {
+	case *apiannotations.HttpRule_Get:
+		return "get", pattern.Get
+	case *apiannotations.HttpRule_Put:
+		return "put", pattern.Put
+	case *apiannotations.HttpRule_Post:
+		return "post", pattern.Post
+	case *apiannotations.HttpRule_Delete:
+		return "delete", pattern.Delete
+	case *apiannotations.HttpRule_Patch:
+		return "patch", pattern.Patch
+	case *apiannotations.HttpRule_Custom:
+		return strings.ToLower(pattern.Custom.GetKind()), pattern.Custom.GetPath()
+	default:
+		return "", ""
+	}
+}
+
+// jsonContent returns an OpenAPI content object for a JSON body with the given schema.
+func jsonContent(schema map[string]any) map[string]any {
+	return map[string]any{
+		"application/json": map[string]any{"schema": schema},
+	}
+}
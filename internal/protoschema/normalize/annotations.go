@@ -0,0 +1,58 @@
+// Copyright 2024-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package normalize
+
+import (
+	"github.com/bufbuild/protoschema-plugins/internal/protoschema/annotations"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// markDeprecatedMessage sets the deprecated option of the given message if it is deprecated by
+// its (buf.protoschema.v1.message) options.
+func markDeprecatedMessage(msgDescPb *descriptorpb.DescriptorProto, msgDesc protoreflect.MessageDescriptor) {
+	if !annotations.GetMessageOptions(msgDesc).GetDeprecated() {
+		return
+	}
+	if msgDescPb.Options == nil {
+		msgDescPb.Options = &descriptorpb.MessageOptions{}
+	}
+	msgDescPb.Options.Deprecated = proto.Bool(true)
+}
+
+// markDeprecatedField sets the deprecated option of the given field if it is deprecated by its
+// (buf.protoschema.v1.field) options.
+func markDeprecatedField(field *descriptorpb.FieldDescriptorProto, fieldDesc protoreflect.FieldDescriptor) {
+	if !annotations.GetFieldOptions(fieldDesc).GetDeprecated() {
+		return
+	}
+	if field.Options == nil {
+		field.Options = &descriptorpb.FieldOptions{}
+	}
+	field.Options.Deprecated = proto.Bool(true)
+}
+
+// markDeprecatedEnumValue sets the deprecated option of the given enum value if it is deprecated
+// by its (buf.protoschema.v1.enum_value) options.
+func markDeprecatedEnumValue(value *descriptorpb.EnumValueDescriptorProto, valueDesc protoreflect.EnumValueDescriptor) {
+	if !annotations.GetEnumValueOptions(valueDesc).GetDeprecated() {
+		return
+	}
+	if value.Options == nil {
+		value.Options = &descriptorpb.EnumValueOptions{}
+	}
+	value.Options.Deprecated = proto.Bool(true)
+}
@@ -0,0 +1,283 @@
+// Copyright 2024-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package normalize
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+)
+
+// extensionState tracks the preserved extensions of the message being normalized.
+type extensionState struct {
+	// resolver resolves the preserved extensions found in the files of the message.
+	resolver *protoregistry.Types
+	// used is the set of preserved extensions set in any options.
+	used map[protoreflect.FullName]protoreflect.ExtensionDescriptor
+	// fields is the set of fields set in the preserved option values, by message name.
+	fields map[protoreflect.FullName]map[protoreflect.FieldNumber]struct{}
+}
+
+// initExtensions resolves the preserved extensions visible from the root message.
+func (n *Normalizer) initExtensions() error {
+	n.extensions = &extensionState{
+		resolver: &protoregistry.Types{},
+		used:     map[protoreflect.FullName]protoreflect.ExtensionDescriptor{},
+		fields:   map[protoreflect.FullName]map[protoreflect.FieldNumber]struct{}{},
+	}
+	for _, name := range n.preservedExtensions {
+		extDesc := findExtension(n.rootDesc.ParentFile(), name, map[string]struct{}{})
+		if extDesc == nil {
+			continue // Not visible, so cannot be set in any options.
+		}
+		if err := n.extensions.resolver.RegisterExtension(dynamicpb.NewExtensionType(extDesc)); err != nil {
+			return fmt.Errorf("failed to register extension %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// preserveExtensions returns the wire encoding of the preserved extensions set in the given
+// options, recording the extensions and the fields of their values that are used.
+//
+// Any extensions and unknown fields within the preserved values are dropped.
+func (n *Normalizer) preserveExtensions(options protoreflect.ProtoMessage) ([]byte, error) {
+	msg := options.ProtoReflect()
+	if n.extensions == nil || !msg.IsValid() {
+		return nil, nil
+	}
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(options)
+	if err != nil {
+		return nil, err
+	}
+	// Resolve only the preserved extensions, which may be unknown fields of the options.
+	resolved := msg.New()
+	if err := (proto.UnmarshalOptions{Resolver: n.extensions.resolver}).Unmarshal(data, resolved.Interface()); err != nil {
+		return nil, err
+	}
+	preserved := resolved.New()
+	resolved.Range(func(field protoreflect.FieldDescriptor, value protoreflect.Value) bool {
+		if !field.IsExtension() {
+			return true
+		}
+		n.extensions.used[field.FullName()] = field
+		n.collectValue(field, value)
+		preserved.Set(field, value)
+		return true
+	})
+	return proto.MarshalOptions{Deterministic: true}.Marshal(preserved.Interface())
+}
+
+// collectValue records the fields set in the given value of the given field, recursively.
+func (n *Normalizer) collectValue(field protoreflect.FieldDescriptor, value protoreflect.Value) {
+	switch {
+	case field.IsMap():
+		value.Map().Range(func(_ protoreflect.MapKey, value protoreflect.Value) bool {
+			n.collectValue(field.MapValue(), value)
+			return true
+		})
+	case field.Message() == nil:
+	case field.IsList():
+		for i := range value.List().Len() {
+			n.collectFields(value.List().Get(i).Message())
+		}
+	default:
+		n.collectFields(value.Message())
+	}
+}
+
+// collectFields records the fields set in the given message, recursively, and drops its
+// extensions and unknown fields.
+func (n *Normalizer) collectFields(msg protoreflect.Message) {
+	fields, ok := n.extensions.fields[msg.Descriptor().FullName()]
+	if !ok {
+		fields = map[protoreflect.FieldNumber]struct{}{}
+		n.extensions.fields[msg.Descriptor().FullName()] = fields
+	}
+	msg.Range(func(field protoreflect.FieldDescriptor, value protoreflect.Value) bool {
+		if field.IsExtension() {
+			msg.Clear(field)
+			return true
+		}
+		fields[field.Number()] = struct{}{}
+		n.collectValue(field, value)
+		return true
+	})
+	msg.SetUnknown(nil)
+}
+
+// inlineExtensions adds the definitions of the used preserved extensions to the root message,
+// along with the types of their values reduced to the fields that are set.
+func (n *Normalizer) inlineExtensions() error {
+	for _, name := range slices.Sorted(maps.Keys(n.extensions.used)) {
+		extDesc := n.extensions.used[name]
+		field, err := n.inlineOptionField(extDesc)
+		if err != nil {
+			return err
+		}
+		field.Name = proto.String(n.addMangledName(string(name), ""))
+		field.Extendee = proto.String("." + string(extDesc.ContainingMessage().FullName()))
+		field.JsonName = nil // Not allowed for extensions.
+		n.imports[extDesc.ContainingMessage().ParentFile().Path()] = extDesc.ContainingMessage().ParentFile()
+		n.rootPb.Extension = append(n.rootPb.Extension, field)
+	}
+	return nil
+}
+
+// inlineOptionMessage inlines the given message type of an option value, reduced to the fields
+// that are set in the preserved option values, and returns its name.
+func (n *Normalizer) inlineOptionMessage(msgDesc protoreflect.MessageDescriptor) (string, error) {
+	name := string(msgDesc.FullName())
+	if ref, ok := n.nameToMangled[name]; ok {
+		return ref, nil // Already inlined.
+	}
+	msg := &descriptorpb.DescriptorProto{
+		Name: proto.String(n.addMangledName(name, "")),
+	}
+	n.inlineMsgs = append(n.inlineMsgs, msg)
+	ref := n.nameToMangled[name]
+	if err := n.inlineOptionFields(msg, msgDesc, ref); err != nil {
+		return "", err
+	}
+	return ref, nil
+}
+
+// inlineOptionFields adds the fields of the given message type of an option value that are set
+// in the preserved option values to the given message, which has the given name.
+func (n *Normalizer) inlineOptionFields(msg *descriptorpb.DescriptorProto, msgDesc protoreflect.MessageDescriptor, ref string) error {
+	oneofs := map[int]int32{}
+	fields := n.extensions.fields[msgDesc.FullName()]
+	for i := range msgDesc.Fields().Len() {
+		fieldDesc := msgDesc.Fields().Get(i)
+		if _, ok := fields[fieldDesc.Number()]; !ok && !msgDesc.IsMapEntry() {
+			continue
+		}
+		field, err := n.inlineOptionField(fieldDesc)
+		if err != nil {
+			return err
+		}
+		if fieldDesc.IsMap() {
+			// Map entries must be nested in the message of the map field.
+			entryDesc := fieldDesc.Message()
+			entry := &descriptorpb.DescriptorProto{
+				Name:    proto.String(string(entryDesc.Name())),
+				Options: &descriptorpb.MessageOptions{MapEntry: proto.Bool(true)},
+			}
+			entryRef := ref + "." + entry.GetName()
+			if err := n.inlineOptionFields(entry, entryDesc, entryRef); err != nil {
+				return err
+			}
+			msg.NestedType = append(msg.NestedType, entry)
+			field.TypeName = proto.String(entryRef)
+		}
+		if oneof := fieldDesc.ContainingOneof(); oneof != nil && !oneof.IsSynthetic() {
+			index, ok := oneofs[oneof.Index()]
+			if !ok {
+				index = int32(len(msg.OneofDecl)) //nolint:gosec
+				oneofs[oneof.Index()] = index
+				msg.OneofDecl = append(msg.OneofDecl, &descriptorpb.OneofDescriptorProto{
+					Name: proto.String(string(oneof.Name())),
+				})
+			}
+			field.OneofIndex = proto.Int32(index)
+		}
+		msg.Field = append(msg.Field, field)
+	}
+	if n.syntax == SyntaxProto3 {
+		addSyntheticOneofs(msg)
+	}
+	return nil
+}
+
+// inlineOptionField returns the given field of an option value, with its type inlined.
+//
+// The entry type of a map field is inlined by the caller.
+func (n *Normalizer) inlineOptionField(fieldDesc protoreflect.FieldDescriptor) (*descriptorpb.FieldDescriptorProto, error) {
+	field := protodesc.ToFieldDescriptorProto(fieldDesc)
+	field.Options = nil
+	field.Extendee = nil
+	field.OneofIndex = nil
+	field.Proto3Optional = nil
+	if err := n.normalizeField(field, fieldDesc); err != nil {
+		return nil, err
+	}
+	switch {
+	case fieldDesc.IsMap():
+	case fieldDesc.Enum() != nil:
+		if n.isInt32Enum(fieldDesc.Enum()) {
+			field.Type = descriptorpb.FieldDescriptorProto_TYPE_INT32.Enum()
+			field.TypeName = nil
+			field.DefaultValue = nil
+		} else if err := n.updateEnumType(fieldDesc.Enum(), field); err != nil {
+			return nil, err
+		}
+	case fieldDesc.Message() != nil:
+		ref, err := n.inlineOptionMessage(fieldDesc.Message())
+		if err != nil {
+			return nil, err
+		}
+		field.TypeName = proto.String(ref)
+	}
+	return field, nil
+}
+
+// findExtension returns the descriptor of the extension with the given name in the given file or
+// its imports, or nil if not found.
+func findExtension(file protoreflect.FileDescriptor, name protoreflect.FullName, seen map[string]struct{}) protoreflect.ExtensionDescriptor {
+	if _, ok := seen[file.Path()]; ok {
+		return nil
+	}
+	seen[file.Path()] = struct{}{}
+	if extDesc := findExtensionIn(file.Extensions(), file.Messages(), name); extDesc != nil {
+		return extDesc
+	}
+	for i := range file.Imports().Len() {
+		if extDesc := findExtension(file.Imports().Get(i).FileDescriptor, name, seen); extDesc != nil {
+			return extDesc
+		}
+	}
+	return nil
+}
+
+func findExtensionIn(extensions protoreflect.ExtensionDescriptors, messages protoreflect.MessageDescriptors, name protoreflect.FullName) protoreflect.ExtensionDescriptor {
+	for i := range extensions.Len() {
+		if extensions.Get(i).FullName() == name {
+			return extensions.Get(i)
+		}
+	}
+	for i := range messages.Len() {
+		msgDesc := messages.Get(i)
+		if extDesc := findExtensionIn(msgDesc.Extensions(), msgDesc.Messages(), name); extDesc != nil {
+			return extDesc
+		}
+	}
+	return nil
+}
+
+// appendUnknown appends the given wire encoded fields to the unknown fields of the given options.
+func appendUnknown(options protoreflect.ProtoMessage, data []byte) {
+	msg := options.ProtoReflect()
+	if len(data) == 0 || !msg.IsValid() {
+		return
+	}
+	msg.SetUnknown(protoreflect.RawFields(append(slices.Clone(msg.GetUnknown()), data...)))
+}
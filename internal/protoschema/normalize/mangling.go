@@ -0,0 +1,91 @@
+// Copyright 2024-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package normalize
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+)
+
+// NameMangling is the scheme used to name the types inlined into the normalized message.
+type NameMangling string
+
+const (
+	// NameManglingFull names inlined types after their fully-qualified name, the default.
+	//
+	// For example, foo.v1.Bar is inlined as Inline_foo_v1_Bar. Names that only differ in their
+	// separators (e.g. foo.bar_baz.Qux and foo_bar.baz.Qux) collide, and are suffixed with a
+	// counter in the order the types are referenced.
+	NameManglingFull NameMangling = "full"
+	// NameManglingShort names inlined types in the package of the normalized message after their
+	// name relative to the package, and other types after their fully-qualified name and its hash.
+	// Names containing underscores are also suffixed with the hash, so the name of an inlined type
+	// only depends on its full name, and not on the other inlined types or the order they are
+	// referenced in.
+	//
+	// For example, foo.v1.Bar is inlined as Inline_Bar into a message in the foo.v1 package, and
+	// bar.v1.Baz as Inline_bar_v1_Baz_<hash>.
+	NameManglingShort NameMangling = "short"
+)
+
+// mangleName returns the mangled name of the type with the given full name.
+func (n *Normalizer) mangleName(name string) string {
+	if n.mangling != NameManglingShort {
+		return "Inline_" + strings.ReplaceAll(name, ".", "_")
+	}
+	relName, external := name, false
+	if pkg := string(n.rootDesc.ParentFile().Package()); pkg != "" {
+		var ok bool
+		relName, ok = strings.CutPrefix(name, pkg+".")
+		// The full name of a type in another package may match the relative name of a type in
+		// the package (e.g. Bar without a package and foo.Bar in foo), so it is always hashed.
+		external = !ok
+	}
+	mangled := "Inline_" + strings.ReplaceAll(relName, ".", "_")
+	if external || strings.Contains(relName, "_") {
+		// Underscores are ambiguous with the separators of the name (e.g. foo.bar_baz and
+		// foo_bar.baz), so the name is made unique by a hash of the full name.
+		mangled += "_" + hashName(name)
+	}
+	return mangled
+}
+
+func (n *Normalizer) addMangledName(name string, subName string) string {
+	mangled := n.mangleName(name)
+	// With NameManglingShort, only a hash collision, or a nested type of the root message named
+	// like an inlined type, needs a suffix.
+	base := mangled
+	for i := 0; ; i++ {
+		if _, ok := n.mangledToName[mangled]; !ok {
+			break
+		}
+		mangled = fmt.Sprintf("%s_%d", base, i)
+	}
+	n.mangledToName[mangled] = name
+	if subName != "" {
+		n.nameToMangled[name] = fmt.Sprintf("%s.%s.%s", n.rootDesc.Name(), mangled, subName)
+	} else {
+		n.nameToMangled[name] = fmt.Sprintf("%s.%s", n.rootDesc.Name(), mangled)
+	}
+	return mangled
+}
+
+// hashName returns a short hash of the given full name.
+func hashName(name string) string {
+	hash := fnv.New32a()
+	_, _ = hash.Write([]byte(name))
+	return fmt.Sprintf("%08x", hash.Sum32())
+}
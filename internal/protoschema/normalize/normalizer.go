@@ -17,6 +17,7 @@ package normalize
 import (
 	"errors"
 	"fmt"
+	"maps"
 	"slices"
 	"strings"
 
@@ -28,13 +29,21 @@ import (
 
 // Normalizer is a normalizer for descriptor protos.
 type Normalizer struct {
-	skipTypes     []string
-	rootDesc      protoreflect.MessageDescriptor
-	rootPb        *descriptorpb.DescriptorProto
-	nameToMangled map[string]string
-	mangledToName map[string]string
-	inlineMsgMap  map[string]*descriptorpb.DescriptorProto
-	inlineMsgs    []*descriptorpb.DescriptorProto
+	skipTypes []string
+	syntax    Syntax
+	mangling  NameMangling
+	// preservedOptions is the set of built-in options that are preserved, or nil for all.
+	preservedOptions map[protoreflect.Name]struct{}
+	// preservedExtensions is the list of extensions that are preserved in options.
+	preservedExtensions []protoreflect.FullName
+	rootDesc            protoreflect.MessageDescriptor
+	rootPb              *descriptorpb.DescriptorProto
+	nameToMangled       map[string]string
+	mangledToName       map[string]string
+	inlineMsgMap        map[string]*descriptorpb.DescriptorProto
+	inlineMsgs          []*descriptorpb.DescriptorProto
+	imports             map[string]protoreflect.FileDescriptor
+	extensions          *extensionState
 }
 
 // NewNormalizer returns a new Normalizer.
@@ -56,20 +65,54 @@ func (n *Normalizer) Normalize(rootDesc protoreflect.MessageDescriptor) (*descri
 		n.nameToMangled[name] = name
 	}
 	n.mangledToName = map[string]string{}
+	// Inlined types are added next to the nested types of the root message.
+	for i := range n.rootDesc.Messages().Len() {
+		nested := n.rootDesc.Messages().Get(i)
+		n.mangledToName[string(nested.Name())] = string(nested.FullName())
+	}
+	for i := range n.rootDesc.Enums().Len() {
+		nested := n.rootDesc.Enums().Get(i)
+		n.mangledToName[string(nested.Name())] = string(nested.FullName())
+	}
 	n.inlineMsgMap = map[string]*descriptorpb.DescriptorProto{}
+	n.imports = map[string]protoreflect.FileDescriptor{}
 
 	if n.rootDesc.ParentFile() != n.rootDesc.Parent() {
 		return nil, errors.New("message must be top-level")
 	}
+	n.extensions = nil
+	if len(n.preservedExtensions) > 0 {
+		if err := n.initExtensions(); err != nil {
+			return nil, err
+		}
+	}
 	n.rootPb = protodesc.ToDescriptorProto(n.rootDesc)
 	if err := n.inlineRefs(n.rootPb, n.rootDesc); err != nil {
 		return nil, err
 	}
+	if n.extensions != nil {
+		if err := n.inlineExtensions(); err != nil {
+			return nil, err
+		}
+	}
 	n.inlineMsgMap[string(n.rootDesc.FullName())] = n.rootPb
 	n.rootPb.NestedType = append(n.rootPb.NestedType, n.inlineMsgs...)
 	return n.rootPb, nil
 }
 
+// Imports returns the files defining the skipped types referenced by the last normalized
+// message, and the options extended by its preserved extensions, sorted by path.
+//
+// Skipped types and extended options are referenced by their full name, so these files must be
+// imported by the file containing the normalized message.
+func (n *Normalizer) Imports() []protoreflect.FileDescriptor {
+	result := make([]protoreflect.FileDescriptor, 0, len(n.imports))
+	for _, filePath := range slices.Sorted(maps.Keys(n.imports)) {
+		result = append(result, n.imports[filePath])
+	}
+	return result
+}
+
 // FindDescriptorProto finds the descriptor proto for the given message descriptor.
 func (n *Normalizer) FindDescriptorProto(msgDesc protoreflect.Descriptor) (*descriptorpb.DescriptorProto, error) {
 	rootMsg, path := findRootAndPath(msgDesc)
@@ -96,6 +139,7 @@ func (n *Normalizer) inlineMessage(msgDesc protoreflect.MessageDescriptor) error
 
 func (n *Normalizer) inlineEnum(enumDesc protoreflect.EnumDescriptor) error {
 	enum := protodesc.ToEnumDescriptorProto(enumDesc)
+	n.normalizeEnum(enum, enumDesc)
 	// Create a message to hold the enum.
 	msg := &descriptorpb.DescriptorProto{
 		Name: proto.String(n.addMangledName(string(enumDesc.FullName()), string(enumDesc.Name()))),
@@ -118,25 +162,36 @@ func (n *Normalizer) inlineRefs(msgDescPb *descriptorpb.DescriptorProto, msgDesc
 	}
 
 	// Strip any custom options.
-	stripExtensionsAndUnknown(msgDescPb.GetOptions())
+	markDeprecatedMessage(msgDescPb, msgDesc)
+	if err := n.stripOptions(msgDescPb.GetOptions()); err != nil {
+		return err
+	}
 	for _, oneOf := range msgDescPb.GetOneofDecl() {
-		stripExtensionsAndUnknown(oneOf.GetOptions())
+		if err := n.stripOptions(oneOf.GetOptions()); err != nil {
+			return err
+		}
 	}
+	n.normalizeMessage(msgDescPb, msgDesc)
 
 	// Remap types in fields.
 	syntheticOneofs := map[int32]struct{}{}
 	for _, field := range msgDescPb.GetField() {
-		stripExtensionsAndUnknown(field.GetOptions())
+		fieldDesc := msgDesc.Fields().ByName(protoreflect.Name(field.GetName()))
+		markDeprecatedField(field, fieldDesc)
+		if err := n.stripOptions(field.GetOptions()); err != nil {
+			return err
+		}
 		if field.GetProto3Optional() {
-			// Since we currently normalize to proto2, we need to
-			// Remove the weird proto3-specific synthetic oneof for
-			// explicit presence fields.
-			// TODO: use editions to normalize and add relevant
-			//       field presence feature to field options here
+			// Remove the proto3-specific synthetic oneof for explicit
+			// presence fields, which is added back by normalizeField
+			// when normalizing to proto3.
 			field.Proto3Optional = nil
 			syntheticOneofs[field.GetOneofIndex()] = struct{}{}
 			field.OneofIndex = nil
 		}
+		if err := n.normalizeField(field, fieldDesc); err != nil {
+			return err
+		}
 		if err := n.inlineFieldRefs(msgDescPb, msgDesc, field); err != nil {
 			return err
 		}
@@ -145,6 +200,9 @@ func (n *Normalizer) inlineRefs(msgDescPb *descriptorpb.DescriptorProto, msgDesc
 	if len(syntheticOneofs) > 0 {
 		n.removeOneofs(msgDescPb, syntheticOneofs)
 	}
+	if n.syntax == SyntaxProto3 {
+		addSyntheticOneofs(msgDescPb)
+	}
 
 	return nil
 }
@@ -154,7 +212,7 @@ func (n *Normalizer) inlineFieldRefs(
 	switch field.GetType() {
 	case descriptorpb.FieldDescriptorProto_TYPE_ENUM:
 		fieldDesc := msgDesc.Fields().ByName(protoreflect.Name(field.GetName()))
-		if !fieldDesc.Enum().IsClosed() {
+		if n.isInt32Enum(fieldDesc.Enum()) {
 			// Convert to int32.
 			field.Type = descriptorpb.FieldDescriptorProto_TYPE_INT32.Enum()
 			field.TypeName = nil
@@ -192,7 +250,6 @@ func (n *Normalizer) removeOneofs(msgDescPb *descriptorpb.DescriptorProto, empty
 		if _, ok := emptyOneofs[idx]; ok {
 			continue
 		}
-		stripExtensionsAndUnknown(oneOf.GetOptions())
 		oneofRemap[idx] = int32(len(newOneofDecl)) //nolint:gosec
 		newOneofDecl = append(newOneofDecl, oneOf)
 	}
@@ -207,6 +264,7 @@ func (n *Normalizer) removeOneofs(msgDescPb *descriptorpb.DescriptorProto, empty
 
 func (n *Normalizer) updateMessageType(refDesc protoreflect.MessageDescriptor, field *descriptorpb.FieldDescriptorProto, path []string) error {
 	ref := string(refDesc.FullName())
+	n.addImport(refDesc)
 	newRef, ok := n.nameToMangled[ref]
 	if !ok {
 		if err := n.inlineMessage(refDesc); err != nil {
@@ -224,6 +282,7 @@ func (n *Normalizer) updateMessageType(refDesc protoreflect.MessageDescriptor, f
 
 func (n *Normalizer) updateEnumType(refDesc protoreflect.EnumDescriptor, field *descriptorpb.FieldDescriptorProto) error {
 	ref := string(refDesc.FullName())
+	n.addImport(refDesc)
 	newRef, ok := n.nameToMangled[ref]
 	if !ok {
 		if err := n.inlineEnum(refDesc); err != nil {
@@ -238,26 +297,11 @@ func (n *Normalizer) updateEnumType(refDesc protoreflect.EnumDescriptor, field *
 	return nil
 }
 
-func (n *Normalizer) mangleName(name string) string {
-	return "Inline_" + strings.ReplaceAll(name, ".", "_")
-}
-
-func (n *Normalizer) addMangledName(name string, subName string) string {
-	mangled := n.mangleName(name)
-	base := mangled
-	for i := 0; ; i++ {
-		if _, ok := n.mangledToName[mangled]; !ok {
-			break
-		}
-		mangled = fmt.Sprintf("%s_%d", base, i)
-	}
-	n.mangledToName[mangled] = name
-	if subName != "" {
-		n.nameToMangled[name] = fmt.Sprintf("%s.%s.%s", n.rootDesc.Name(), mangled, subName)
-	} else {
-		n.nameToMangled[name] = fmt.Sprintf("%s.%s", n.rootDesc.Name(), mangled)
+// addImport records the file of the given type if it is skipped.
+func (n *Normalizer) addImport(refDesc protoreflect.Descriptor) {
+	if slices.Contains(n.skipTypes, string(refDesc.FullName())) {
+		n.imports[refDesc.ParentFile().Path()] = refDesc.ParentFile()
 	}
-	return mangled
 }
 
 func findNestedMessage(msg *descriptorpb.DescriptorProto, name string) *descriptorpb.DescriptorProto {
@@ -285,6 +329,45 @@ func findRootAndPath(target protoreflect.Descriptor) (protoreflect.MessageDescri
 	return msg, path
 }
 
+// stripOptions strips the extensions, unknown fields and non-preserved built-in options from
+// the given options.
+//
+// Preserved extensions are kept as unknown fields, which are resolved by the extensions inlined
+// into the normalized message.
+func (n *Normalizer) stripOptions(options protoreflect.ProtoMessage) error {
+	preserved, err := n.preserveExtensions(options)
+	if err != nil {
+		return fmt.Errorf("failed to preserve extensions: %w", err)
+	}
+	stripExtensionsAndUnknown(options)
+	n.stripBuiltinOptions(options)
+	appendUnknown(options, preserved)
+	return nil
+}
+
+// stripBuiltinOptions strips the built-in options that are not preserved from the given options.
+//
+// Options that affect the encoding of messages are always preserved.
+func (n *Normalizer) stripBuiltinOptions(options protoreflect.ProtoMessage) {
+	msg := options.ProtoReflect()
+	if n.preservedOptions == nil || !msg.IsValid() {
+		return
+	}
+	msg.Range(func(field protoreflect.FieldDescriptor, _ protoreflect.Value) bool {
+		if field.IsExtension() {
+			return true
+		}
+		switch field.Name() {
+		case "map_entry", "message_set_wire_format", "packed", "features":
+			return true
+		}
+		if _, ok := n.preservedOptions[field.Name()]; !ok {
+			msg.Clear(field)
+		}
+		return true
+	})
+}
+
 func stripExtensionsAndUnknown(options protoreflect.ProtoMessage) {
 	msg := options.ProtoReflect()
 	if !msg.IsValid() {
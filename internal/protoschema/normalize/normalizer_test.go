@@ -18,7 +18,9 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
 	"google.golang.org/protobuf/types/descriptorpb"
 )
 
@@ -136,6 +138,116 @@ func TestNormalize_ThreeLevelNesting(t *testing.T) {
 	require.Equal(t, "Organization.Department.Team", teamsField.GetTypeName())
 }
 
+func TestNormalize_Syntax(t *testing.T) {
+	t.Parallel()
+	fd := &descriptorpb.FileDescriptorProto{
+		Name:    strPtr("test.proto"),
+		Package: strPtr("test.v1"),
+		Syntax:  strPtr("proto2"),
+		MessageType: []*descriptorpb.DescriptorProto{
+			{
+				Name: strPtr("Message"),
+				Field: []*descriptorpb.FieldDescriptorProto{
+					{
+						Name:   strPtr("required_int32"),
+						Number: int32Ptr(1),
+						Type:   enumPtr(descriptorpb.FieldDescriptorProto_TYPE_INT32),
+						Label:  labelPtr(descriptorpb.FieldDescriptorProto_LABEL_REQUIRED),
+					},
+					{
+						Name:         strPtr("optional_int32"),
+						Number:       int32Ptr(2),
+						Type:         enumPtr(descriptorpb.FieldDescriptorProto_TYPE_INT32),
+						Label:        labelPtr(descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL),
+						DefaultValue: strPtr("5"),
+					},
+					{
+						Name:   strPtr("unpacked_int32"),
+						Number: int32Ptr(3),
+						Type:   enumPtr(descriptorpb.FieldDescriptorProto_TYPE_INT32),
+						Label:  labelPtr(descriptorpb.FieldDescriptorProto_LABEL_REPEATED),
+					},
+					{
+						Name:    strPtr("packed_int32"),
+						Number:  int32Ptr(4),
+						Type:    enumPtr(descriptorpb.FieldDescriptorProto_TYPE_INT32),
+						Label:   labelPtr(descriptorpb.FieldDescriptorProto_LABEL_REPEATED),
+						Options: &descriptorpb.FieldOptions{Packed: proto.Bool(true)},
+					},
+					{
+						Name:     strPtr("closed_enum"),
+						Number:   int32Ptr(5),
+						Type:     enumPtr(descriptorpb.FieldDescriptorProto_TYPE_ENUM),
+						TypeName: strPtr(".test.v1.Message.Enum"),
+						Label:    labelPtr(descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL),
+					},
+				},
+				EnumType: []*descriptorpb.EnumDescriptorProto{
+					{
+						Name: strPtr("Enum"),
+						Value: []*descriptorpb.EnumValueDescriptorProto{
+							{Name: strPtr("ENUM_ONE"), Number: int32Ptr(1)},
+						},
+					},
+				},
+				ExtensionRange: []*descriptorpb.DescriptorProto_ExtensionRange{
+					{Start: int32Ptr(100), End: int32Ptr(200)},
+				},
+			},
+		},
+	}
+	files, err := protodesc.NewFiles(&descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{fd}})
+	require.NoError(t, err)
+	fileDesc, err := files.FindFileByPath("test.proto")
+	require.NoError(t, err)
+	msgDesc := fileDesc.Messages().ByName("Message")
+	require.NotNil(t, msgDesc)
+
+	for _, syntax := range []Syntax{SyntaxProto2, SyntaxProto3, SyntaxEditions} {
+		t.Run(string(syntax), func(t *testing.T) {
+			t.Parallel()
+			result, err := NewNormalizer(WithSyntax(syntax)).Normalize(msgDesc)
+			require.NoError(t, err)
+			file := &descriptorpb.FileDescriptorProto{
+				Name:        strPtr("normalized.proto"),
+				Syntax:      strPtr(string(syntax)),
+				MessageType: []*descriptorpb.DescriptorProto{result},
+			}
+			if syntax == SyntaxEditions {
+				file.Edition = Edition.Enum()
+			}
+			normalized, err := protodesc.NewFile(file, nil)
+			require.NoError(t, err)
+			got := normalized.Messages().Get(0)
+
+			for i := range msgDesc.Fields().Len() {
+				want := msgDesc.Fields().Get(i)
+				field := got.Fields().ByName(want.Name())
+				require.NotNil(t, field, want.Name())
+				require.Equal(t, want.HasPresence(), field.HasPresence(), want.Name())
+				require.Equal(t, want.IsPacked(), field.IsPacked(), want.Name())
+			}
+			required := got.Fields().ByName("required_int32")
+			optional := got.Fields().ByName("optional_int32")
+			closedEnum := got.Fields().ByName("closed_enum")
+			switch syntax {
+			case SyntaxProto3:
+				// Required fields, defaults and closed enums cannot be represented in proto3.
+				require.Equal(t, protoreflect.Optional, required.Cardinality())
+				require.False(t, optional.HasDefault())
+				require.Equal(t, protoreflect.Int32Kind, closedEnum.Kind())
+				require.Zero(t, got.ExtensionRanges().Len())
+			default:
+				require.Equal(t, protoreflect.Required, required.Cardinality())
+				require.Equal(t, int64(5), optional.Default().Int())
+				require.Equal(t, protoreflect.EnumKind, closedEnum.Kind())
+				require.True(t, closedEnum.Enum().IsClosed())
+				require.Equal(t, 1, got.ExtensionRanges().Len())
+			}
+		})
+	}
+}
+
 func strPtr(s string) *string { return &s }
 func int32Ptr(i int32) *int32 { return &i }
 
@@ -146,3 +258,114 @@ func enumPtr(e descriptorpb.FieldDescriptorProto_Type) *descriptorpb.FieldDescri
 func labelPtr(l descriptorpb.FieldDescriptorProto_Label) *descriptorpb.FieldDescriptorProto_Label {
 	return &l
 }
+
+// buildManglingDescriptor creates a file descriptor with a Root message referencing the given
+// fields, of which "underscore" and "nested" have types whose names differ only in separators,
+// "global" has a type without a package whose name is the relative name of "other", and "snake"
+// has a type in a package containing underscores.
+func buildManglingDescriptor(t *testing.T, fieldNames ...string) protoreflect.MessageDescriptor {
+	t.Helper()
+	types := map[string]string{
+		"underscore": ".test.v1.Bar_Baz",
+		"nested":     ".test.v1.Bar.Baz",
+		"other":      ".test.v1.Qux",
+		"external":   ".other.v1.Qux",
+		"global":     ".Qux",
+		"snake":      ".my_co.v1.Foo",
+	}
+	root := &descriptorpb.DescriptorProto{Name: strPtr("Root")}
+	for i, fieldName := range fieldNames {
+		root.Field = append(root.Field, &descriptorpb.FieldDescriptorProto{
+			Name:     strPtr(fieldName),
+			Number:   int32Ptr(int32(i + 1)), //nolint:gosec
+			Type:     enumPtr(descriptorpb.FieldDescriptorProto_TYPE_MESSAGE),
+			TypeName: strPtr(types[fieldName]),
+			Label:    labelPtr(descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL),
+		})
+	}
+	files, err := protodesc.NewFiles(&descriptorpb.FileDescriptorSet{File: []*descriptorpb.FileDescriptorProto{
+		{
+			Name:        strPtr("other.proto"),
+			Package:     strPtr("other.v1"),
+			Syntax:      strPtr("proto3"),
+			MessageType: []*descriptorpb.DescriptorProto{{Name: strPtr("Qux")}},
+		},
+		{
+			Name:        strPtr("snake.proto"),
+			Package:     strPtr("my_co.v1"),
+			Syntax:      strPtr("proto3"),
+			MessageType: []*descriptorpb.DescriptorProto{{Name: strPtr("Foo")}},
+		},
+		{
+			Name:        strPtr("global.proto"),
+			Syntax:      strPtr("proto3"),
+			MessageType: []*descriptorpb.DescriptorProto{{Name: strPtr("Qux")}},
+		},
+		{
+			Name:       strPtr("test.proto"),
+			Package:    strPtr("test.v1"),
+			Syntax:     strPtr("proto3"),
+			Dependency: []string{"other.proto", "snake.proto", "global.proto"},
+			MessageType: []*descriptorpb.DescriptorProto{
+				root,
+				{Name: strPtr("Bar_Baz")},
+				{Name: strPtr("Bar"), NestedType: []*descriptorpb.DescriptorProto{{Name: strPtr("Baz")}}},
+				{Name: strPtr("Qux")},
+			},
+		},
+	}})
+	require.NoError(t, err)
+	desc, err := files.FindDescriptorByName("test.v1.Root")
+	require.NoError(t, err)
+	msgDesc, ok := desc.(protoreflect.MessageDescriptor)
+	require.True(t, ok)
+	return msgDesc
+}
+
+func TestNormalize_StableNames(t *testing.T) {
+	t.Parallel()
+	getTypeNames := func(t *testing.T, msgDesc protoreflect.MessageDescriptor, options ...NormalizerOption) map[string]string {
+		t.Helper()
+		result, err := NewNormalizer(options...).Normalize(msgDesc)
+		require.NoError(t, err)
+		typeNames := map[string]string{}
+		for _, field := range result.GetField() {
+			typeNames[field.GetName()] = field.GetTypeName()
+		}
+		return typeNames
+	}
+
+	// The default names are the fully-qualified names, with underscores in place of the dots.
+	all := getTypeNames(t, buildManglingDescriptor(t, "underscore", "nested", "other", "external", "snake"))
+	require.Equal(t, map[string]string{
+		"underscore": "Root.Inline_test_v1_Bar_Baz",
+		"nested":     "Root.Inline_test_v1_Bar.Baz",
+		"other":      "Root.Inline_test_v1_Qux",
+		"external":   "Root.Inline_other_v1_Qux",
+		"snake":      "Root.Inline_my_co_v1_Foo",
+	}, all)
+
+	// Reordering fields does not rename the inlined types.
+	require.Equal(t, all, getTypeNames(t, buildManglingDescriptor(t, "snake", "external", "other", "nested", "underscore")))
+
+	// Adding or removing fields does not rename the other inlined types.
+	for _, fieldName := range []string{"underscore", "nested", "other", "external", "snake"} {
+		require.Equal(t, all[fieldName], getTypeNames(t, buildManglingDescriptor(t, fieldName))[fieldName], fieldName)
+	}
+
+	short := getTypeNames(t, buildManglingDescriptor(t, "external", "global", "other", "nested", "underscore", "snake"), WithNameMangling(NameManglingShort))
+	require.Equal(t, map[string]string{
+		"snake":      "Root.Inline_my_co_v1_Foo_" + hashName("my_co.v1.Foo"),
+		"underscore": "Root.Inline_Bar_Baz_" + hashName("test.v1.Bar_Baz"),
+		"nested":     "Root.Inline_Bar.Baz",
+		"other":      "Root.Inline_Qux",
+		"external":   "Root.Inline_other_v1_Qux_" + hashName("other.v1.Qux"),
+		"global":     "Root.Inline_Qux_" + hashName("Qux"),
+	}, short)
+
+	// Types outside the package never collide with the types in the package, regardless of order.
+	require.Equal(t, short, getTypeNames(t, buildManglingDescriptor(t, "snake", "global", "underscore", "nested", "other", "external"), WithNameMangling(NameManglingShort)))
+	for _, fieldName := range []string{"external", "global", "other", "snake"} {
+		require.Equal(t, short[fieldName], getTypeNames(t, buildManglingDescriptor(t, fieldName), WithNameMangling(NameManglingShort))[fieldName], fieldName)
+	}
+}
@@ -14,12 +14,58 @@
 
 package normalize
 
+import "google.golang.org/protobuf/reflect/protoreflect"
+
 // NormalizerOption is an option for a new Normalizer.
 type NormalizerOption func(*Normalizer)
 
 // WithSkipTypes returns a new NormalizerOption that skips the given types.
+//
+// Skipped types are not inlined, and are referenced by their full name instead. See Imports.
 func WithSkipTypes(skipTypes ...string) NormalizerOption {
 	return func(n *Normalizer) {
-		n.skipTypes = skipTypes
+		n.skipTypes = append(n.skipTypes, skipTypes...)
+	}
+}
+
+// WithSyntax returns a new NormalizerOption that normalizes messages for a file of the given
+// syntax, instead of proto2.
+func WithSyntax(syntax Syntax) NormalizerOption {
+	return func(n *Normalizer) {
+		n.syntax = syntax
+	}
+}
+
+// WithNameMangling returns a new NormalizerOption that names inlined types with the given scheme,
+// instead of NameManglingFull.
+func WithNameMangling(mangling NameMangling) NormalizerOption {
+	return func(n *Normalizer) {
+		n.mangling = mangling
+	}
+}
+
+// WithPreservedOptions returns a new NormalizerOption that only preserves the given built-in
+// options (e.g. "deprecated") of messages, fields, oneofs, enums and enum values.
+//
+// By default, all built-in options are preserved. Options that affect the encoding, such as
+// "packed" and "map_entry", are always preserved.
+func WithPreservedOptions(names ...protoreflect.Name) NormalizerOption {
+	return func(n *Normalizer) {
+		n.preservedOptions = make(map[protoreflect.Name]struct{}, len(names))
+		for _, name := range names {
+			n.preservedOptions[name] = struct{}{}
+		}
+	}
+}
+
+// WithPreservedExtensions returns a new NormalizerOption that preserves the given extensions
+// (e.g. "buf.validate.field") in the options of messages, fields and oneofs.
+//
+// By default, all extensions are stripped. The definitions of the preserved extensions that are
+// set are inlined into the normalized message, with their message types reduced to the fields
+// that are set.
+func WithPreservedExtensions(names ...protoreflect.FullName) NormalizerOption {
+	return func(n *Normalizer) {
+		n.preservedExtensions = append(n.preservedExtensions, names...)
 	}
 }
@@ -0,0 +1,222 @@
+// Copyright 2024-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package normalize
+
+import (
+	"fmt"
+	"slices"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// Syntax is the syntax of the file containing the normalized messages.
+type Syntax string
+
+const (
+	// SyntaxProto2 normalizes to proto2, the default.
+	//
+	// Open enums are converted to int32 fields, and all singular fields have explicit presence.
+	SyntaxProto2 Syntax = "proto2"
+	// SyntaxProto3 normalizes to proto3.
+	//
+	// Fields with explicit presence are proto3 optional fields, and unpacked repeated fields are
+	// marked as such. Closed enums are converted to int32 fields, and required fields, default
+	// values and extension ranges are dropped. Groups are not supported.
+	SyntaxProto3 Syntax = "proto3"
+	// SyntaxEditions normalizes to Edition 2023.
+	//
+	// Field presence, enum openness, repeated field encoding and message encoding are preserved
+	// with features.
+	SyntaxEditions Syntax = "editions"
+)
+
+// Edition is the edition of the file containing messages normalized to SyntaxEditions.
+const Edition = descriptorpb.Edition_EDITION_2023
+
+// normalizeMessage updates the nested enums and extension ranges of the given message for the
+// target syntax.
+func (n *Normalizer) normalizeMessage(msgDescPb *descriptorpb.DescriptorProto, msgDesc protoreflect.MessageDescriptor) {
+	if n.syntax != SyntaxEditions {
+		clearFeatures(msgDescPb.GetOptions())
+		for _, oneOf := range msgDescPb.GetOneofDecl() {
+			clearFeatures(oneOf.GetOptions())
+		}
+	}
+	if n.syntax == SyntaxProto3 {
+		// Closed enums cannot be defined in proto3, and are only referenced as int32 fields.
+		msgDescPb.EnumType = slices.DeleteFunc(msgDescPb.EnumType, func(enum *descriptorpb.EnumDescriptorProto) bool {
+			return msgDesc.Enums().ByName(protoreflect.Name(enum.GetName())).IsClosed()
+		})
+		msgDescPb.ExtensionRange = nil
+	}
+	for _, enum := range msgDescPb.GetEnumType() {
+		n.normalizeEnum(enum, msgDesc.Enums().ByName(protoreflect.Name(enum.GetName())))
+	}
+}
+
+// normalizeEnum updates the given enum for the target syntax.
+func (n *Normalizer) normalizeEnum(enum *descriptorpb.EnumDescriptorProto, enumDesc protoreflect.EnumDescriptor) {
+	n.stripBuiltinOptions(enum.GetOptions())
+	for _, value := range enum.GetValue() {
+		markDeprecatedEnumValue(value, enumDesc.Values().ByName(protoreflect.Name(value.GetName())))
+		n.stripBuiltinOptions(value.GetOptions())
+	}
+	if n.syntax != SyntaxEditions {
+		clearFeatures(enum.GetOptions())
+		return
+	}
+	if enumDesc.IsClosed() {
+		if enum.Options == nil {
+			enum.Options = &descriptorpb.EnumOptions{}
+		}
+		if enum.Options.Features == nil {
+			enum.Options.Features = &descriptorpb.FeatureSet{}
+		}
+		enum.Options.Features.EnumType = descriptorpb.FeatureSet_CLOSED.Enum()
+	}
+}
+
+// normalizeField updates the given field to preserve the semantics of the field descriptor in
+// the target syntax.
+func (n *Normalizer) normalizeField(field *descriptorpb.FieldDescriptorProto, fieldDesc protoreflect.FieldDescriptor) error {
+	if fieldDesc.ContainingMessage().IsMapEntry() {
+		// Map entries have the same semantics in all syntaxes.
+		clearFeatures(field.GetOptions())
+		return nil
+	}
+	switch n.syntax {
+	case SyntaxProto3:
+		clearFeatures(field.GetOptions())
+		if fieldDesc.Kind() == protoreflect.GroupKind {
+			return fmt.Errorf("group field %s cannot be normalized to proto3", fieldDesc.FullName())
+		}
+		field.DefaultValue = nil
+		if field.GetLabel() == descriptorpb.FieldDescriptorProto_LABEL_REQUIRED {
+			field.Label = descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()
+		}
+		if fieldDesc.HasPresence() && fieldDesc.Message() == nil && !isInOneof(fieldDesc) && !fieldDesc.IsExtension() {
+			// The synthetic oneof is added once all fields are normalized.
+			field.Proto3Optional = proto.Bool(true)
+		}
+		if isPackable(fieldDesc) && !fieldDesc.IsPacked() {
+			// Repeated scalar fields are packed by default in proto3.
+			fieldOptions(field).Packed = proto.Bool(false)
+		}
+	case SyntaxEditions:
+		if field.GetOptions() != nil {
+			field.Options.Packed = nil
+		}
+		if fieldDesc.Kind() == protoreflect.GroupKind {
+			field.Type = descriptorpb.FieldDescriptorProto_TYPE_MESSAGE.Enum()
+			fieldFeatures(field).MessageEncoding = descriptorpb.FeatureSet_DELIMITED.Enum()
+		}
+		switch {
+		case field.GetLabel() == descriptorpb.FieldDescriptorProto_LABEL_REQUIRED:
+			field.Label = descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL.Enum()
+			fieldFeatures(field).FieldPresence = descriptorpb.FeatureSet_LEGACY_REQUIRED.Enum()
+		case !fieldDesc.HasPresence() && fieldDesc.Cardinality() != protoreflect.Repeated:
+			fieldFeatures(field).FieldPresence = descriptorpb.FeatureSet_IMPLICIT.Enum()
+		}
+		if isPackable(fieldDesc) && !fieldDesc.IsPacked() {
+			fieldFeatures(field).RepeatedFieldEncoding = descriptorpb.FeatureSet_EXPANDED.Enum()
+		}
+	default:
+		clearFeatures(field.GetOptions())
+	}
+	return nil
+}
+
+// isInt32Enum returns true if fields of the given enum are normalized to int32 fields, as the
+// openness of the enum cannot be represented in the target syntax.
+func (n *Normalizer) isInt32Enum(enumDesc protoreflect.EnumDescriptor) bool {
+	switch n.syntax {
+	case SyntaxProto3:
+		return enumDesc.IsClosed()
+	case SyntaxEditions:
+		return false
+	default:
+		return !enumDesc.IsClosed()
+	}
+}
+
+// addSyntheticOneofs adds a synthetic oneof for each proto3 optional field of the given message.
+//
+// Synthetic oneofs must follow all other oneofs, so they are added once the other oneofs are final.
+func addSyntheticOneofs(msgDescPb *descriptorpb.DescriptorProto) {
+	for _, field := range msgDescPb.GetField() {
+		if !field.GetProto3Optional() {
+			continue
+		}
+		// Follow protoc in naming the oneof after the field, prefixed with "X" until unique.
+		name := "_" + field.GetName()
+		for slices.ContainsFunc(msgDescPb.GetOneofDecl(), func(oneOf *descriptorpb.OneofDescriptorProto) bool {
+			return oneOf.GetName() == name
+		}) {
+			name = "X" + name
+		}
+		field.OneofIndex = proto.Int32(int32(len(msgDescPb.GetOneofDecl()))) //nolint:gosec
+		msgDescPb.OneofDecl = append(msgDescPb.OneofDecl, &descriptorpb.OneofDescriptorProto{
+			Name: proto.String(name),
+		})
+	}
+}
+
+// isInOneof returns true if the given field is in a (non-synthetic) oneof.
+func isInOneof(fieldDesc protoreflect.FieldDescriptor) bool {
+	oneOf := fieldDesc.ContainingOneof()
+	return oneOf != nil && !oneOf.IsSynthetic()
+}
+
+// isPackable returns true if the given field is a repeated field of a scalar type that can use
+// the packed encoding.
+func isPackable(fieldDesc protoreflect.FieldDescriptor) bool {
+	if !fieldDesc.IsList() {
+		return false
+	}
+	switch fieldDesc.Kind() {
+	case protoreflect.StringKind, protoreflect.BytesKind, protoreflect.MessageKind, protoreflect.GroupKind:
+		return false
+	default:
+		return true
+	}
+}
+
+func fieldOptions(field *descriptorpb.FieldDescriptorProto) *descriptorpb.FieldOptions {
+	if field.Options == nil {
+		field.Options = &descriptorpb.FieldOptions{}
+	}
+	return field.Options
+}
+
+func fieldFeatures(field *descriptorpb.FieldDescriptorProto) *descriptorpb.FeatureSet {
+	options := fieldOptions(field)
+	if options.Features == nil {
+		options.Features = &descriptorpb.FeatureSet{}
+	}
+	return options.Features
+}
+
+// clearFeatures removes any features from the given options, which are only valid in editions.
+func clearFeatures(options proto.Message) {
+	msg := options.ProtoReflect()
+	if !msg.IsValid() {
+		return
+	}
+	if field := msg.Descriptor().Fields().ByName("features"); field != nil {
+		msg.Clear(field)
+	}
+}
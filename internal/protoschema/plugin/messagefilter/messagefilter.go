@@ -0,0 +1,132 @@
+// Copyright 2024-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package messagefilter selects the messages the plugins generate schemas for, based on the
+// include, include_regex and include_directive parameters.
+package messagefilter
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Filter selects the messages that are used as entry points for generation.
+//
+// By default, all messages are selected. If any include patterns or the directive are set, only
+// the matching messages are selected.
+type Filter struct {
+	// IncludeNested also selects nested messages when collecting unfiltered messages.
+	IncludeNested bool
+	// Directive selects messages with the given comment directive in their leading comments, if
+	// not empty.
+	Directive string
+	// include is the list of patterns matched against the full name of the message.
+	include []*regexp.Regexp
+}
+
+// AddGlobs adds the given globs of full names, delimited by '+', e.g. "foo.v1.*+bar.**".
+//
+// A '*' matches any part of a single name component, and '**' matches any number of components.
+func (f *Filter) AddGlobs(value string) {
+	for glob := range strings.SplitSeq(value, "+") {
+		f.include = append(f.include, parseGlob(strings.TrimSpace(glob)))
+	}
+}
+
+// AddRegex adds the given regular expression, which must match the full name of a message.
+func (f *Filter) AddRegex(value string) error {
+	pattern, err := regexp.Compile("^(?:" + value + ")$")
+	if err != nil {
+		return fmt.Errorf("invalid include_regex %q: %w", value, err)
+	}
+	f.include = append(f.include, pattern)
+	return nil
+}
+
+// IsFiltered returns true if any include patterns or the directive are set.
+func (f *Filter) IsFiltered() bool {
+	return len(f.include) > 0 || f.Directive != ""
+}
+
+// Matches returns true if the given message is selected.
+func (f *Filter) Matches(desc protoreflect.MessageDescriptor) bool {
+	if !f.IsFiltered() {
+		return true
+	}
+	for _, pattern := range f.include {
+		if pattern.MatchString(string(desc.FullName())) {
+			return true
+		}
+	}
+	if f.Directive != "" {
+		srcLoc := desc.ParentFile().SourceLocations().ByDescriptor(desc)
+		return hasDirective(srcLoc.LeadingComments, f.Directive)
+	}
+	return false
+}
+
+// Collect appends the selected messages in the given list to the result.
+//
+// Nested messages are collected if the filter is set or IncludeNested is true. Map entries are
+// never collected.
+func (f *Filter) Collect(messages protoreflect.MessageDescriptors, result []protoreflect.MessageDescriptor) []protoreflect.MessageDescriptor {
+	for i := range messages.Len() {
+		messageDescriptor := messages.Get(i)
+		if messageDescriptor.IsMapEntry() {
+			continue
+		}
+		if f.Matches(messageDescriptor) {
+			result = append(result, messageDescriptor)
+		}
+		if f.IncludeNested || f.IsFiltered() {
+			result = f.Collect(messageDescriptor.Messages(), result)
+		}
+	}
+	return result
+}
+
+// hasDirective returns true if the given comments contain the directive as a whole word, so
+// that e.g. "jsonschema:generated" or "no-jsonschema:generate" do not match.
+func hasDirective(comments string, directive string) bool {
+	return slices.Contains(strings.Fields(comments), directive)
+}
+
+// parseGlob converts a glob of full names into a regular expression.
+func parseGlob(glob string) *regexp.Regexp {
+	var result strings.Builder
+	result.WriteString("^")
+	for glob != "" {
+		switch {
+		case strings.HasPrefix(glob, "**"):
+			result.WriteString(".*")
+			glob = glob[2:]
+		case strings.HasPrefix(glob, "*"):
+			result.WriteString(`[^.]*`)
+			glob = glob[1:]
+		default:
+			end := strings.IndexByte(glob, '*')
+			if end < 0 {
+				end = len(glob)
+			}
+			result.WriteString(regexp.QuoteMeta(glob[:end]))
+			glob = glob[end:]
+		}
+	}
+	result.WriteString("$")
+	return regexp.MustCompile(result.String())
+}
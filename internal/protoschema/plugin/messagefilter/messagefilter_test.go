@@ -0,0 +1,114 @@
+// Copyright 2024-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package messagefilter
+
+import (
+	"testing"
+
+	"github.com/bufbuild/protocompile"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+func TestFilter(t *testing.T) {
+	t.Parallel()
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{
+			Accessor: protocompile.SourceAccessorFromMap(map[string]string{
+				"shop/v1/order.proto": `
+					syntax = "proto3";
+					package shop.v1;
+					// jsonschema:generate
+					message Order {
+					  message Item {}
+					  map<string, string> labels = 1;
+					}
+					// jsonschema:generated by a tool.
+					message OrderRequest {}
+					message Customer {
+					  // The address.
+					  //
+					  // jsonschema:generate
+					  message Address {}
+					}
+				`,
+			}),
+		}),
+		SourceInfoMode: protocompile.SourceInfoStandard,
+	}
+	files, err := compiler.Compile(t.Context(), "shop/v1/order.proto")
+	require.NoError(t, err)
+	messages := files[0].Messages()
+	collect := func(filter *Filter) []protoreflect.FullName {
+		var result []protoreflect.FullName
+		for _, desc := range filter.Collect(messages, nil) {
+			result = append(result, desc.FullName())
+		}
+		return result
+	}
+
+	t.Run("default", func(t *testing.T) {
+		t.Parallel()
+		filter := &Filter{}
+		require.False(t, filter.IsFiltered())
+		require.Equal(t, []protoreflect.FullName{"shop.v1.Order", "shop.v1.OrderRequest", "shop.v1.Customer"}, collect(filter))
+		filter.IncludeNested = true
+		require.Equal(t, []protoreflect.FullName{
+			"shop.v1.Order", "shop.v1.Order.Item", "shop.v1.OrderRequest", "shop.v1.Customer", "shop.v1.Customer.Address",
+		}, collect(filter))
+	})
+	t.Run("globs", func(t *testing.T) {
+		t.Parallel()
+		filter := &Filter{}
+		filter.AddGlobs("shop.v1.Order* + **.Address")
+		require.True(t, filter.IsFiltered())
+		require.Equal(t, []protoreflect.FullName{"shop.v1.Order", "shop.v1.OrderRequest", "shop.v1.Customer.Address"}, collect(filter))
+		filter = &Filter{}
+		filter.AddGlobs("shop.*.Order.*")
+		require.Equal(t, []protoreflect.FullName{"shop.v1.Order.Item"}, collect(filter))
+	})
+	t.Run("regex", func(t *testing.T) {
+		t.Parallel()
+		filter := &Filter{}
+		require.NoError(t, filter.AddRegex(`shop\.v1\.Order\..*`))
+		require.Equal(t, []protoreflect.FullName{"shop.v1.Order.Item"}, collect(filter))
+		require.Error(t, filter.AddRegex("("))
+	})
+	t.Run("directive", func(t *testing.T) {
+		t.Parallel()
+		filter := &Filter{Directive: "jsonschema:generate"}
+		require.Equal(t, []protoreflect.FullName{"shop.v1.Order", "shop.v1.Customer.Address"}, collect(filter))
+	})
+}
+
+func TestHasDirective(t *testing.T) {
+	t.Parallel()
+	const directive = "jsonschema:generate"
+	tests := []struct {
+		comments string
+		want     bool
+	}{
+		{comments: " jsonschema:generate\n", want: true},
+		{comments: " The request.\n\n jsonschema:generate\n", want: true},
+		{comments: " See jsonschema:generate for details.\n", want: true},
+		{comments: " jsonschema:generated by a tool.\n", want: false},
+		{comments: " no-jsonschema:generate\n", want: false},
+		{comments: " jsonschema:generate:false\n", want: false},
+		{comments: "", want: false},
+	}
+	for _, test := range tests {
+		require.Equal(t, test.want, hasDirective(test.comments, directive), test.comments)
+	}
+}
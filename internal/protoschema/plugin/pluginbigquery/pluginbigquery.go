@@ -0,0 +1,89 @@
+// Copyright 2024-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pluginbigquery
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bufbuild/protoplugin"
+	"github.com/bufbuild/protoschema-plugins/internal/protoschema/bigquery"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// Handle implements protoplugin.Handler and is the main entry point for the plugin.
+func Handle(
+	_ context.Context,
+	_ protoplugin.PluginEnv,
+	responseWriter protoplugin.ResponseWriter,
+	request protoplugin.Request,
+) error {
+	fileDescriptors, err := request.FileDescriptorsToGenerate()
+	if err != nil {
+		return err
+	}
+	// Parse the parameters from the request.
+	opts, err := parseOptions(request.Parameter())
+	if err != nil {
+		return err
+	}
+	for _, fileDescriptor := range fileDescriptors {
+		for i := range fileDescriptor.Messages().Len() {
+			messageDescriptor := fileDescriptor.Messages().Get(i)
+			data, err := bigquery.Generate(messageDescriptor, opts...)
+			if err != nil {
+				return err
+			}
+			responseWriter.AddFile(
+				fmt.Sprintf("%s.%s", messageDescriptor.FullName(), bigquery.FileExtension),
+				data,
+			)
+		}
+	}
+
+	responseWriter.SetFeatureProto3Optional()
+	responseWriter.SetFeatureSupportsEditions(descriptorpb.Edition_EDITION_2023, descriptorpb.Edition_EDITION_2023)
+	return nil
+}
+
+func parseOptions(param string) ([]bigquery.GeneratorOption, error) {
+	if param == "" {
+		return nil, nil
+	}
+	var result []bigquery.GeneratorOption
+	// Params are in the form of "key1=value1,key2=value2"
+	for param := range strings.SplitSeq(param, ",") {
+		// Split the param into key and value.
+		key, value, ok := strings.Cut(param, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid parameter %q, expected key=value", param)
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		switch key {
+		case "max_depth":
+			maxDepth, err := strconv.Atoi(value)
+			if err != nil || maxDepth < 1 {
+				return nil, fmt.Errorf("invalid max_depth %q, expected a positive integer", value)
+			}
+			result = append(result, bigquery.WithMaxDepth(maxDepth))
+		default:
+			return nil, fmt.Errorf("unknown parameter %q", param)
+		}
+	}
+	return result, nil
+}
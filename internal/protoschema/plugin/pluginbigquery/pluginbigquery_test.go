@@ -0,0 +1,126 @@
+// Copyright 2024-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pluginbigquery
+
+import (
+	"bytes"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/bufbuild/buf/private/bufpkg/bufimage"
+	imagev1 "github.com/bufbuild/buf/private/gen/proto/go/buf/alpha/image/v1"
+	"github.com/bufbuild/buf/private/pkg/protoencoding"
+	"github.com/bufbuild/protoplugin"
+	_ "github.com/bufbuild/protoschema-plugins/internal/gen/proto/buf/protoschema/test/v1"
+	"github.com/bufbuild/protoschema-plugins/internal/protoschema/bigquery"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/pluginpb"
+)
+
+func TestBigQueryHandler(t *testing.T) {
+	t.Parallel()
+
+	// The default maximum depth is used for the golden files.
+	for _, parameter := range []string{"", "max_depth=1"} {
+		t.Run(parameter, func(t *testing.T) {
+			t.Parallel()
+			testBigQueryHandler(t, parameter)
+		})
+	}
+}
+
+func TestParseOptions(t *testing.T) {
+	t.Parallel()
+
+	opts, err := parseOptions("max_depth=3")
+	require.NoError(t, err)
+	require.Len(t, opts, 1)
+	for _, parameter := range []string{"max_depth=0", "max_depth=abc", "max_depth", "depth=1"} {
+		_, err := parseOptions(parameter)
+		require.Error(t, err, parameter)
+	}
+}
+
+func testBigQueryHandler(t *testing.T, parameter string) {
+	t.Helper()
+
+	goldenPath := filepath.FromSlash("../../../testdata/bigquery")
+	inputImage := filepath.FromSlash("../../../testdata/codegenrequest/input.json")
+
+	by, err := os.ReadFile(inputImage)
+	require.NoError(t, err)
+	protoImage := new(imagev1.Image)
+	err = protojson.Unmarshal(by, protoImage)
+	require.NoError(t, err)
+	image, err := bufimage.NewImageForProto(protoImage)
+	require.NoError(t, err)
+	codeGeneratorRequest, err := bufimage.ImageToCodeGeneratorRequest(image, parameter, nil, false, false)
+	require.NoError(t, err)
+
+	request, err := protoencoding.NewWireMarshaler().Marshal(codeGeneratorRequest)
+	require.NoError(t, err)
+	stdin := bytes.NewReader(request)
+	stdout := bytes.NewBuffer(nil)
+	stderr := bytes.NewBuffer(nil)
+	err = protoplugin.Run(
+		t.Context(),
+		protoplugin.Env{
+			Stdin:  stdin,
+			Stdout: stdout,
+			Stderr: stderr,
+		},
+		protoplugin.HandlerFunc(Handle),
+	)
+	require.NoError(t, err)
+	require.Empty(t, stderr.String())
+
+	response := new(pluginpb.CodeGeneratorResponse)
+	err = protoencoding.NewWireUnmarshaler(nil).Unmarshal(stdout.Bytes(), response)
+	require.NoError(t, err)
+
+	wantFiles := make([]string, 0, len(response.GetFile()))
+	for _, file := range response.GetFile() {
+		wantFiles = append(wantFiles, file.GetName())
+	}
+	slices.Sort(wantFiles)
+	require.Equal(t, wantFiles, gatherGoldenFiles(t, goldenPath))
+
+	for _, file := range response.GetFile() {
+		filename := path.Join(goldenPath, file.GetName())
+		want, err := os.ReadFile(filename)
+		require.NoError(t, err)
+		require.Equal(t, string(want), file.GetContent())
+	}
+}
+
+func gatherGoldenFiles(t *testing.T, dir string) []string {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	var files []string
+	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), bigquery.FileExtension) {
+			files = append(files, entry.Name())
+		}
+	}
+	slices.Sort(files)
+	return files
+}
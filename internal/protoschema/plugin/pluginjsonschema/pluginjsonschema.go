@@ -19,12 +19,15 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"maps"
 	"slices"
 	"strings"
 
 	"github.com/bufbuild/protoplugin"
 	"github.com/bufbuild/protoschema-plugins/internal/protoschema/jsonschema"
+	"github.com/bufbuild/protoschema-plugins/internal/protoschema/plugin/messagefilter"
 	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
 	"google.golang.org/protobuf/types/descriptorpb"
 )
 
@@ -40,42 +43,71 @@ func Handle(
 		return err
 	}
 
+	allFiles, err := request.AllFiles()
+	if err != nil {
+		return err
+	}
 	// Parse the parameters from the request.
-	opts, err := parseOptions(request.Parameter())
+	targets, filter, err := parseOptions(request.Parameter(), allFiles)
 	if err != nil {
 		return err
 	}
 
-	gens := make([]*jsonschema.Generator, len(opts))
-	for i, opt := range opts {
-		gens[i] = jsonschema.NewGenerator(opt...)
+	gens := make([]*jsonschema.Generator, len(targets))
+	for i, target := range targets {
+		gens[i] = jsonschema.NewGenerator(target.opts...)
 	}
 
-	// Generate the JSON schema for each message descriptor.
+	// Generate the JSON schema for each selected message descriptor.
 	for _, fileDescriptor := range fileDescriptors {
-		for i := range fileDescriptor.Messages().Len() {
-			messageDescriptor := fileDescriptor.Messages().Get(i)
+		for _, messageDescriptor := range filter.Collect(fileDescriptor.Messages(), nil) {
 			for _, gen := range gens {
 				if err := gen.Add(messageDescriptor); err != nil {
 					return err
 				}
 			}
 		}
+		// Services are only included in OpenAPI documents.
+		for i := range fileDescriptor.Services().Len() {
+			serviceDescriptor := fileDescriptor.Services().Get(i)
+			for j, gen := range gens {
+				if !targets[j].openAPI {
+					continue
+				}
+				if err := gen.AddService(serviceDescriptor); err != nil {
+					return err
+				}
+			}
+		}
 	}
 
-	for _, gen := range gens {
-		if err := writeFiles(responseWriter, gen.Generate()); err != nil {
+	// Collect the files of all generators, so they are written in a stable order.
+	files := make(map[string]string)
+	for i, gen := range gens {
+		if targets[i].openAPI {
+			documents, err := gen.GenerateOpenAPI()
+			if err != nil {
+				return err
+			}
+			if err := collectOpenAPIFiles(files, documents); err != nil {
+				return err
+			}
+		} else if err := collectFiles(files, gen.Generate()); err != nil {
 			return err
 		}
 	}
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		responseWriter.AddFile(name, files[name])
+	}
 
 	responseWriter.SetFeatureProto3Optional()
 	responseWriter.SetFeatureSupportsEditions(descriptorpb.Edition_EDITION_2023, descriptorpb.Edition_EDITION_2023)
 	return nil
 }
 
-func writeFiles(
-	responseWriter protoplugin.ResponseWriter,
+// collectFiles adds the serialized schemas to the given files, keyed by file name.
+func collectFiles(
+	files map[string]string,
 	schema map[protoreflect.FullName]map[string]any,
 ) error {
 	for _, entry := range schema {
@@ -90,16 +122,36 @@ func writeFiles(
 		if identifier == "" {
 			return errors.New("expected unique id to be a non-empty string")
 		}
-		responseWriter.AddFile(
-			identifier,
-			string(data)+"\n",
-		)
+		files[identifier] = string(data) + "\n"
+	}
+	return nil
+}
+
+// collectOpenAPIFiles adds the serialized OpenAPI documents to the given files, keyed by file name.
+func collectOpenAPIFiles(
+	files map[string]string,
+	documents map[string]map[string]any,
+) error {
+	for name, document := range documents {
+		data, err := json.MarshalIndent(document, "", "  ")
+		if err != nil {
+			return err
+		}
+		files[name] = string(data) + "\n"
 	}
 	return nil
 }
 
-func parseOptions(param string) ([][]jsonschema.GeneratorOption, error) {
+// targetOptions are the generator options for a single target.
+type targetOptions struct {
+	opts []jsonschema.GeneratorOption
+	// openAPI is true if the target generates OpenAPI documents instead of JSON schema files.
+	openAPI bool
+}
+
+func parseOptions(param string, files *protoregistry.Files) ([]targetOptions, *messagefilter.Filter, error) {
 	var baseOpts []jsonschema.GeneratorOption
+	filter := &messagefilter.Filter{}
 
 	targets := make(map[string]struct{})
 	if param != "" { // nolint:nestif
@@ -108,17 +160,55 @@ func parseOptions(param string) ([][]jsonschema.GeneratorOption, error) {
 			// Split the param into key and value.
 			key, value, ok := strings.Cut(param, "=")
 			if !ok {
-				return nil, fmt.Errorf("invalid parameter %q, expected key=value", param)
+				return nil, nil, fmt.Errorf("invalid parameter %q, expected key=value", param)
 			}
 			key = strings.TrimSpace(key)
 			value = strings.TrimSpace(value)
 			switch key {
 			case "additional_properties":
 				if value, err := parseBoolean(value); err != nil {
-					return nil, err
+					return nil, nil, err
 				} else if value {
 					baseOpts = append(baseOpts, jsonschema.WithAdditionalProperties())
 				}
+			case "include_nested":
+				if value, err := parseBoolean(value); err != nil {
+					return nil, nil, err
+				} else if value {
+					filter.IncludeNested = true
+				}
+			case "include":
+				filter.AddGlobs(value)
+			case "include_regex":
+				if err := filter.AddRegex(value); err != nil {
+					return nil, nil, err
+				}
+			case "include_directive":
+				if value, err := parseBoolean(value); err != nil {
+					return nil, nil, err
+				} else if value {
+					filter.Directive = generateDirective
+				}
+			case "enum_schemas":
+				if value, err := parseBoolean(value); err != nil {
+					return nil, nil, err
+				} else if value {
+					baseOpts = append(baseOpts, jsonschema.WithEnumSchemas())
+				}
+			case "any_types":
+				// Types are delimited by '+', e.g. "foo.v1.Bar+foo.v1.Baz".
+				for name := range strings.SplitSeq(value, "+") {
+					name = strings.TrimSpace(name)
+					desc, err := files.FindDescriptorByName(protoreflect.FullName(name))
+					if err != nil {
+						return nil, nil, fmt.Errorf("failed to find any type %q: %w", name, err)
+					}
+					msgDesc, ok := desc.(protoreflect.MessageDescriptor)
+					if !ok {
+						return nil, nil, fmt.Errorf("any type %q is not a message", name)
+					}
+					baseOpts = append(baseOpts, jsonschema.WithAnyTypes(msgDesc))
+				}
 			case "target":
 				// Targets are delimited by '+', e.g. "proto+json".
 				targetsList := strings.Split(value, "+")
@@ -126,13 +216,20 @@ func parseOptions(param string) ([][]jsonschema.GeneratorOption, error) {
 					targets[strings.ToLower(strings.TrimSpace(target))] = struct{}{}
 				}
 			default:
-				return nil, fmt.Errorf("unknown parameter %q", param)
+				return nil, nil, fmt.Errorf("unknown parameter %q", param)
 			}
 		}
 	}
-	return generateOptions(baseOpts, targets)
+	result, err := generateOptions(baseOpts, targets)
+	if err != nil {
+		return nil, nil, err
+	}
+	return result, filter, nil
 }
 
+// generateDirective is the comment directive that selects a message as an entry point.
+const generateDirective = "jsonschema:generate"
+
 var allTargets = map[string]struct{}{
 	"proto":               {},
 	"proto-bundle":        {},
@@ -144,17 +241,42 @@ var allTargets = map[string]struct{}{
 	"json-strict-bundle":  {},
 }
 
-func generateOptions(baseOpts []jsonschema.GeneratorOption, targets map[string]struct{}) ([][]jsonschema.GeneratorOption, error) {
-	if _, ok := targets["all"]; ok || len(targets) == 0 {
+// variantSuffixes are the suffixes of the targets that generate a variant of the schemas.
+var variantSuffixes = map[string]jsonschema.GeneratorOption{
+	"-input":  jsonschema.WithInput(),
+	"-output": jsonschema.WithOutput(),
+	"-patch":  jsonschema.WithPatch(),
+}
+
+func generateOptions(baseOpts []jsonschema.GeneratorOption, targets map[string]struct{}) ([]targetOptions, error) {
+	if _, ok := targets["all"]; ok {
+		// The OpenAPI targets are not included in "all", as they are an alternate format.
+		delete(targets, "all")
+		maps.Copy(targets, allTargets)
+	} else if len(targets) == 0 {
 		targets = allTargets
 	}
 
-	var result [][]jsonschema.GeneratorOption
+	var result []targetOptions
+	var targetOpts []jsonschema.GeneratorOption
 	appendOpts := func(opts ...jsonschema.GeneratorOption) {
-		result = append(result, append(slices.Clone(baseOpts), opts...))
+		result = append(result, targetOptions{opts: append(slices.Clone(targetOpts), opts...)})
+	}
+	appendOpenAPIOpts := func(opts ...jsonschema.GeneratorOption) {
+		result = append(result, targetOptions{opts: append(slices.Clone(targetOpts), opts...), openAPI: true})
 	}
-	for target := range targets {
-		switch target {
+	for _, target := range slices.Sorted(maps.Keys(targets)) {
+		// Variants are suffixed to the target, e.g. "json-input".
+		name := target
+		targetOpts = baseOpts
+		for suffix, opt := range variantSuffixes {
+			if base, ok := strings.CutSuffix(target, suffix); ok {
+				name = base
+				targetOpts = append(slices.Clone(baseOpts), opt)
+				break
+			}
+		}
+		switch name {
 		case "proto":
 			appendOpts()
 		case "proto-bundle":
@@ -171,6 +293,14 @@ func generateOptions(baseOpts []jsonschema.GeneratorOption, targets map[string]s
 			appendOpts(jsonschema.WithJSONNames(), jsonschema.WithStrict())
 		case "json-strict-bundle":
 			appendOpts(jsonschema.WithJSONNames(), jsonschema.WithStrict(), jsonschema.WithBundle())
+		case "proto-openapi":
+			appendOpenAPIOpts()
+		case "proto-strict-openapi":
+			appendOpenAPIOpts(jsonschema.WithStrict())
+		case "json-openapi":
+			appendOpenAPIOpts(jsonschema.WithJSONNames())
+		case "json-strict-openapi":
+			appendOpenAPIOpts(jsonschema.WithJSONNames(), jsonschema.WithStrict())
 		default:
 			return nil, fmt.Errorf("unknown target %q", target)
 		}
@@ -16,10 +16,12 @@ package pluginjsonschema
 
 import (
 	"bytes"
+	"encoding/json"
 	"os"
 	"path"
 	"path/filepath"
 	"slices"
+	"strings"
 	"testing"
 
 	"github.com/bufbuild/buf/private/bufpkg/bufimage"
@@ -36,8 +38,184 @@ func TestJSONSchemaHandler(t *testing.T) {
 	t.Parallel()
 
 	goldenPath := filepath.FromSlash("../../../testdata/jsonschema")
-	inputImage := filepath.FromSlash("../../../testdata/codegenrequest/input.json")
+	response := runHandler(t, "")
+
+	wantFiles := make([]string, 0, len(response.GetFile()))
+	for _, file := range response.GetFile() {
+		wantFiles = append(wantFiles, file.GetName())
+	}
+	slices.Sort(wantFiles)
+	require.Equal(t, wantFiles, gatherGoldenFiles(t, goldenPath))
+
+	for _, file := range response.GetFile() {
+		filename := path.Join(goldenPath, file.GetName())
+		want, err := os.ReadFile(filename)
+		require.NoError(t, err)
+		require.Equal(t, string(want), file.GetContent())
+	}
+}
+
+func TestJSONSchemaHandlerEntryPoints(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		parameter string
+		want      []string
+	}{
+		{
+			name:      "nested",
+			parameter: "include_nested=true",
+			want: []string{
+				"buf.protoschema.test.v1.EntryPoint.Request.schema.bundle.json",
+				"buf.protoschema.test.v1.EntryPoint.Response.schema.bundle.json",
+				"buf.protoschema.test.v1.EntryPoint.schema.bundle.json",
+			},
+		},
+		{
+			name:      "glob",
+			parameter: "include=buf.protoschema.test.v1.EntryPoint.*",
+			want: []string{
+				"buf.protoschema.test.v1.EntryPoint.Request.schema.bundle.json",
+				"buf.protoschema.test.v1.EntryPoint.Response.schema.bundle.json",
+			},
+		},
+		{
+			name:      "glob_recursive",
+			parameter: "include=buf.protoschema.test.v1.Entry**",
+			want: []string{
+				"buf.protoschema.test.v1.EntryPoint.Request.schema.bundle.json",
+				"buf.protoschema.test.v1.EntryPoint.Response.schema.bundle.json",
+				"buf.protoschema.test.v1.EntryPoint.schema.bundle.json",
+			},
+		},
+		{
+			name:      "regex",
+			parameter: "include_regex=.*\\.Entry[a-zA-Z]+\\.Res.*",
+			want: []string{
+				"buf.protoschema.test.v1.EntryPoint.Response.schema.bundle.json",
+			},
+		},
+		{
+			name:      "directive",
+			parameter: "include_directive=true",
+			want: []string{
+				"buf.protoschema.test.v1.EntryPoint.Request.schema.bundle.json",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+			response := runHandler(t, test.parameter+",target=proto-bundle")
+			var gotFiles []string
+			for _, file := range response.GetFile() {
+				if strings.HasPrefix(file.GetName(), "buf.protoschema.test.v1.EntryPoint") {
+					gotFiles = append(gotFiles, file.GetName())
+				}
+			}
+			slices.Sort(gotFiles)
+			require.Equal(t, test.want, gotFiles)
+		})
+	}
+}
+
+func TestJSONSchemaHandlerOpenAPI(t *testing.T) {
+	t.Parallel()
+
+	response := runHandler(t, "target=proto-openapi+json-strict-openapi")
+	gotFiles := make([]string, 0, len(response.GetFile()))
+	for _, file := range response.GetFile() {
+		gotFiles = append(gotFiles, file.GetName())
+	}
+	require.Equal(t, []string{
+		"buf.protoschema.test.v1.jsonschema.strict.openapi.json",
+		"buf.protoschema.test.v1.schema.openapi.json",
+		"buf.protoschema.v1.jsonschema.strict.openapi.json",
+		"buf.protoschema.v1.schema.openapi.json",
+		"bufext.cel.expr.conformance.proto3.jsonschema.strict.openapi.json",
+		"bufext.cel.expr.conformance.proto3.schema.openapi.json",
+	}, gotFiles)
+}
+
+func TestJSONSchemaHandlerVariants(t *testing.T) {
+	t.Parallel()
+
+	response := runHandler(t, "target=json+json-input+proto-strict-bundle-output+proto-strict-bundle-patch")
+	files := make(map[string]map[string]any)
+	for _, file := range response.GetFile() {
+		var schema map[string]any
+		err := json.Unmarshal([]byte(file.GetContent()), &schema)
+		require.NoError(t, err)
+		files[file.GetName()] = schema
+	}
+	defaultSchema, ok := files["buf.protoschema.test.v1.SchemaOptions.jsonschema.json"]
+	require.True(t, ok)
+	require.Contains(t, defaultSchema["properties"], "id")
+	require.Contains(t, defaultSchema["properties"], "password")
 
+	// Output only fields are dropped from the input variant.
+	inputSchema, ok := files["buf.protoschema.test.v1.SchemaOptions.jsonschema.input.json"]
+	require.True(t, ok)
+	require.Equal(t, "buf.protoschema.test.v1.SchemaOptions.jsonschema.input.json", inputSchema["$id"])
+	require.NotContains(t, inputSchema["properties"], "id")
+	require.Contains(t, inputSchema["properties"], "password")
+
+	// Input only fields are dropped from the output variant.
+	outputSchema, ok := files["buf.protoschema.test.v1.SchemaOptions.schema.strict.output.bundle.json"]
+	require.True(t, ok)
+	require.Equal(t, "#/$defs/buf.protoschema.test.v1.SchemaOptions.schema.strict.output.json", outputSchema["$ref"])
+	defs, ok := outputSchema["$defs"].(map[string]any)
+	require.True(t, ok)
+	outputDef, ok := defs["buf.protoschema.test.v1.SchemaOptions.schema.strict.output.json"].(map[string]any)
+	require.True(t, ok)
+	require.Contains(t, outputDef["properties"], "id")
+	require.NotContains(t, outputDef["properties"], "password")
+
+	// Nothing is required by the patch variant, which is bundled next to the other variants.
+	patchSchema, ok := files["buf.protoschema.test.v1.Product.schema.strict.patch.bundle.json"]
+	require.True(t, ok)
+	defs, ok = patchSchema["$defs"].(map[string]any)
+	require.True(t, ok)
+	patchDef, ok := defs["buf.protoschema.test.v1.Product.schema.strict.patch.json"].(map[string]any)
+	require.True(t, ok)
+	require.NotContains(t, patchDef, "required")
+	require.Contains(t, files, "buf.protoschema.test.v1.Product.schema.strict.output.bundle.json")
+
+	_, _, err := parseOptions("target=json-sideways-input", nil)
+	require.Error(t, err)
+}
+
+func TestJSONSchemaHandlerDeterministic(t *testing.T) {
+	t.Parallel()
+
+	want := runPlugin(t, "")
+	for range 5 {
+		require.Equal(t, want, runPlugin(t, ""))
+	}
+
+	response := new(pluginpb.CodeGeneratorResponse)
+	err := protoencoding.NewWireUnmarshaler(nil).Unmarshal(want, response)
+	require.NoError(t, err)
+	require.True(t, slices.IsSortedFunc(response.GetFile(), func(a, b *pluginpb.CodeGeneratorResponse_File) int {
+		return strings.Compare(a.GetName(), b.GetName())
+	}))
+}
+
+func runHandler(t *testing.T, parameter string) *pluginpb.CodeGeneratorResponse {
+	t.Helper()
+
+	response := new(pluginpb.CodeGeneratorResponse)
+	err := protoencoding.NewWireUnmarshaler(nil).Unmarshal(runPlugin(t, parameter), response)
+	require.NoError(t, err)
+	return response
+}
+
+// runPlugin runs the plugin on the test image and returns the serialized response.
+func runPlugin(t *testing.T, parameter string) []byte {
+	t.Helper()
+
+	inputImage := filepath.FromSlash("../../../testdata/codegenrequest/input.json")
 	by, err := os.ReadFile(inputImage)
 	require.NoError(t, err)
 	protoImage := new(imagev1.Image)
@@ -45,7 +223,7 @@ func TestJSONSchemaHandler(t *testing.T) {
 	require.NoError(t, err)
 	image, err := bufimage.NewImageForProto(protoImage)
 	require.NoError(t, err)
-	codeGeneratorRequest, err := bufimage.ImageToCodeGeneratorRequest(image, "", nil, false, false)
+	codeGeneratorRequest, err := bufimage.ImageToCodeGeneratorRequest(image, parameter, nil, false, false)
 	require.NoError(t, err)
 
 	request, err := protoencoding.NewWireMarshaler().Marshal(codeGeneratorRequest)
@@ -64,24 +242,7 @@ func TestJSONSchemaHandler(t *testing.T) {
 	)
 	require.NoError(t, err)
 	require.Empty(t, stderr.String())
-
-	response := new(pluginpb.CodeGeneratorResponse)
-	err = protoencoding.NewWireUnmarshaler(nil).Unmarshal(stdout.Bytes(), response)
-	require.NoError(t, err)
-
-	wantFiles := make([]string, 0, len(response.GetFile()))
-	for _, file := range response.GetFile() {
-		wantFiles = append(wantFiles, file.GetName())
-	}
-	slices.Sort(wantFiles)
-	require.Equal(t, wantFiles, gatherGoldenFiles(t, goldenPath))
-
-	for _, file := range response.GetFile() {
-		filename := path.Join(goldenPath, file.GetName())
-		want, err := os.ReadFile(filename)
-		require.NoError(t, err)
-		require.Equal(t, string(want), file.GetContent())
-	}
+	return stdout.Bytes()
 }
 
 func gatherGoldenFiles(t *testing.T, dir string) []string {
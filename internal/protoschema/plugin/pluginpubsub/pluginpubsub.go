@@ -17,9 +17,16 @@ package pluginpubsub
 import (
 	"context"
 	"fmt"
+	"path"
+	"strings"
+	"text/template"
 
 	"github.com/bufbuild/protoplugin"
+	"github.com/bufbuild/protoschema-plugins/internal/protoschema/normalize"
+	"github.com/bufbuild/protoschema-plugins/internal/protoschema/plugin/messagefilter"
 	"github.com/bufbuild/protoschema-plugins/internal/protoschema/pubsub"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
 	"google.golang.org/protobuf/types/descriptorpb"
 )
 
@@ -34,17 +41,55 @@ func Handle(
 	if err != nil {
 		return err
 	}
+	// Parse the parameters from the request.
+	opts, err := parseOptions(request.Parameter())
+	if err != nil {
+		return err
+	}
+	// The file names of the generated messages, to detect conflicts.
+	fileNames := make(map[string]protoreflect.FullName)
 	for _, fileDescriptor := range fileDescriptors {
 		for i := range fileDescriptor.Messages().Len() {
 			messageDescriptor := fileDescriptor.Messages().Get(i)
-			data, err := pubsub.Generate(messageDescriptor)
+			if !opts.filter.Matches(messageDescriptor) {
+				continue
+			}
+			fileName, err := opts.getFileName(messageDescriptor)
 			if err != nil {
 				return err
 			}
-			responseWriter.AddFile(
-				fmt.Sprintf("%s.%s", messageDescriptor.FullName(), pubsub.FileExtension),
-				data,
-			)
+			if other, ok := fileNames[fileName]; ok {
+				return fmt.Errorf("file name %q of %q is already used by %q", fileName, messageDescriptor.FullName(), other)
+			}
+			fileNames[fileName] = messageDescriptor.FullName()
+			if opts.proto {
+				data, err := pubsub.Generate(
+					messageDescriptor,
+					pubsub.WithSyntax(opts.syntax),
+					pubsub.WithNormalizerOptions(opts.normalizerOpts...),
+				)
+				if err != nil {
+					return err
+				}
+				responseWriter.AddFile(
+					fmt.Sprintf("%s.%s", fileName, pubsub.FileExtension),
+					data,
+				)
+			}
+			if opts.avro {
+				data, err := pubsub.GenerateAvro(
+					messageDescriptor,
+					pubsub.WithSyntax(opts.syntax),
+					pubsub.WithNormalizerOptions(opts.normalizerOpts...),
+				)
+				if err != nil {
+					return err
+				}
+				responseWriter.AddFile(
+					fmt.Sprintf("%s.%s", fileName, pubsub.AvroFileExtension),
+					data,
+				)
+			}
 		}
 	}
 
@@ -52,3 +97,175 @@ func Handle(
 	responseWriter.SetFeatureSupportsEditions(descriptorpb.Edition_EDITION_2023, descriptorpb.Edition_EDITION_2023)
 	return nil
 }
+
+// options are the parsed parameters of the plugin.
+type options struct {
+	// proto is true if the protobuf schema is generated.
+	proto bool
+	// avro is true if the Avro schema is generated.
+	avro bool
+	// syntax is the syntax of the protobuf schema.
+	syntax normalize.Syntax
+	// normalizerOpts are the options used to normalize the protobuf schema.
+	normalizerOpts []normalize.NormalizerOption
+	// filter selects the top-level messages that are generated. If not set, all top-level
+	// messages are generated.
+	filter messagefilter.Filter
+	// fileName is the template of the file names, without the extension.
+	fileName *template.Template
+}
+
+// fileNameData is the data of the file name template.
+type fileNameData struct {
+	// FullName is the full name of the message, e.g. "foo.v1.Bar".
+	FullName string
+	// Name is the name of the message, e.g. "Bar".
+	Name string
+	// Package is the package of the message, e.g. "foo.v1".
+	Package string
+	// File is the path of the file defining the message, without the extension, e.g. "foo/v1/bar".
+	File string
+}
+
+// getFileName returns the name of the generated files for the given message, without the extension.
+func (o *options) getFileName(desc protoreflect.MessageDescriptor) (string, error) {
+	if o.fileName == nil {
+		return string(desc.FullName()), nil
+	}
+	var result strings.Builder
+	if err := o.fileName.Execute(&result, fileNameData{
+		FullName: string(desc.FullName()),
+		Name:     string(desc.Name()),
+		Package:  string(desc.ParentFile().Package()),
+		File:     strings.TrimSuffix(desc.ParentFile().Path(), path.Ext(desc.ParentFile().Path())),
+	}); err != nil {
+		return "", fmt.Errorf("failed to execute file_name template for %q: %w", desc.FullName(), err)
+	}
+	if result.Len() == 0 {
+		return "", fmt.Errorf("file_name template is empty for %q", desc.FullName())
+	}
+	return result.String(), nil
+}
+
+func parseOptions(param string) (*options, error) {
+	result := &options{proto: true, syntax: normalize.SyntaxProto2}
+	if param == "" {
+		return result, nil
+	}
+	// The Avro schema is self-contained and has no options, so the parameters that import types
+	// or preserve options are only supported for the protobuf schema.
+	var protoOnlyParams []string
+	// Params are in the form of "key1=value1,key2=value2"
+	for param := range strings.SplitSeq(param, ",") {
+		// Split the param into key and value.
+		key, value, ok := strings.Cut(param, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid parameter %q, expected key=value", param)
+		}
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+		switch key {
+		case "format":
+			switch value {
+			case "proto":
+				result.proto, result.avro = true, false
+			case "avro":
+				result.proto, result.avro = false, true
+			case "both":
+				result.proto, result.avro = true, true
+			default:
+				return nil, fmt.Errorf("invalid format %q, expected proto, avro or both", value)
+			}
+		case "syntax":
+			switch syntax := normalize.Syntax(value); syntax {
+			case normalize.SyntaxProto2, normalize.SyntaxProto3, normalize.SyntaxEditions:
+				result.syntax = syntax
+			default:
+				return nil, fmt.Errorf("invalid syntax %q, expected proto2, proto3 or editions", value)
+			}
+		case "name_mangling":
+			switch mangling := normalize.NameMangling(value); mangling {
+			case normalize.NameManglingFull, normalize.NameManglingShort:
+				result.normalizerOpts = append(result.normalizerOpts, normalize.WithNameMangling(mangling))
+			default:
+				return nil, fmt.Errorf("invalid name_mangling %q, expected full or short", value)
+			}
+		case "skip_types":
+			protoOnlyParams = append(protoOnlyParams, key)
+			// Types are delimited by '+', e.g. "foo.v1.Bar+foo.v1.Baz".
+			var skipTypes []string
+			for name := range strings.SplitSeq(value, "+") {
+				name = strings.TrimSpace(name)
+				if !protoreflect.FullName(name).IsValid() {
+					return nil, fmt.Errorf("invalid skip type %q, expected a full name", name)
+				}
+				skipTypes = append(skipTypes, name)
+			}
+			result.normalizerOpts = append(result.normalizerOpts, normalize.WithSkipTypes(skipTypes...))
+		case "preserve_options":
+			protoOnlyParams = append(protoOnlyParams, key)
+			// Options are delimited by '+', e.g. "deprecated+ctype".
+			if value == "all" {
+				continue
+			}
+			var names []protoreflect.Name
+			if value != "none" {
+				for name := range strings.SplitSeq(value, "+") {
+					name := protoreflect.Name(strings.TrimSpace(name))
+					if !isBuiltinOption(name) {
+						return nil, fmt.Errorf("invalid option %q, expected all, none or a built-in option name", name)
+					}
+					names = append(names, name)
+				}
+			}
+			result.normalizerOpts = append(result.normalizerOpts, normalize.WithPreservedOptions(names...))
+		case "preserve_extensions":
+			protoOnlyParams = append(protoOnlyParams, key)
+			// Extensions are delimited by '+', e.g. "buf.validate.field+buf.validate.message".
+			var names []protoreflect.FullName
+			for name := range strings.SplitSeq(value, "+") {
+				name := protoreflect.FullName(strings.TrimSpace(name))
+				if !name.IsValid() {
+					return nil, fmt.Errorf("invalid extension %q, expected a full name", name)
+				}
+				names = append(names, name)
+			}
+			result.normalizerOpts = append(result.normalizerOpts, normalize.WithPreservedExtensions(names...))
+		case "include":
+			result.filter.AddGlobs(value)
+		case "include_regex":
+			if err := result.filter.AddRegex(value); err != nil {
+				return nil, err
+			}
+		case "file_name":
+			fileName, err := template.New("file_name").Parse(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid file_name %q: %w", value, err)
+			}
+			result.fileName = fileName
+		default:
+			return nil, fmt.Errorf("unknown parameter %q", param)
+		}
+	}
+	if result.avro && len(protoOnlyParams) > 0 {
+		return nil, fmt.Errorf("parameter %q is not supported with format avro or both", protoOnlyParams[0])
+	}
+	return result, nil
+}
+
+// isBuiltinOption returns true if the given name is a built-in option of messages, fields,
+// oneofs, enums or enum values.
+func isBuiltinOption(name protoreflect.Name) bool {
+	for _, options := range []proto.Message{
+		&descriptorpb.MessageOptions{},
+		&descriptorpb.FieldOptions{},
+		&descriptorpb.OneofOptions{},
+		&descriptorpb.EnumOptions{},
+		&descriptorpb.EnumValueOptions{},
+	} {
+		if options.ProtoReflect().Descriptor().Fields().ByName(name) != nil {
+			return true
+		}
+	}
+	return false
+}
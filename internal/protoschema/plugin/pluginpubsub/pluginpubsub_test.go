@@ -38,6 +38,159 @@ func TestPubsubHandler(t *testing.T) {
 	t.Parallel()
 
 	goldenPath := filepath.FromSlash("../../../testdata/pubsub")
+	tests := []struct {
+		parameter  string
+		goldenPath string
+		extensions []string
+	}{
+		{parameter: "", goldenPath: goldenPath, extensions: []string{pubsub.FileExtension}},
+		{parameter: "format=proto", goldenPath: goldenPath, extensions: []string{pubsub.FileExtension}},
+		{parameter: "format=avro", goldenPath: goldenPath, extensions: []string{pubsub.AvroFileExtension}},
+		{parameter: "format=both", goldenPath: goldenPath, extensions: []string{pubsub.FileExtension, pubsub.AvroFileExtension}},
+		{parameter: "syntax=proto2", goldenPath: goldenPath, extensions: []string{pubsub.FileExtension}},
+		{parameter: "syntax=proto3", goldenPath: filepath.Join(goldenPath, "proto3"), extensions: []string{pubsub.FileExtension}},
+		{parameter: "syntax=editions", goldenPath: filepath.Join(goldenPath, "editions"), extensions: []string{pubsub.FileExtension}},
+	}
+	for _, test := range tests {
+		t.Run(test.parameter, func(t *testing.T) {
+			t.Parallel()
+			testPubsubHandler(t, test.parameter, test.goldenPath, test.extensions)
+		})
+	}
+}
+
+func TestParseOptions(t *testing.T) {
+	t.Parallel()
+
+	for _, parameter := range []string{
+		"format=json",
+		"syntax=proto4",
+		"syntax",
+		"unknown=true",
+		"skip_types=foo..Bar",
+		"preserve_options=unknown",
+		"preserve_extensions=buf..field",
+		"name_mangling=long",
+		"include_regex=(",
+		"file_name={{.Name",
+		"format=avro,skip_types=google.protobuf.Timestamp",
+		"preserve_options=none,format=both",
+		"format=both,preserve_extensions=buf.validate.field",
+	} {
+		_, err := parseOptions(parameter)
+		require.Error(t, err, parameter)
+	}
+}
+
+func TestPubsubHandlerOptions(t *testing.T) {
+	t.Parallel()
+
+	t.Run("include", func(t *testing.T) {
+		t.Parallel()
+		response, err := runPubsubHandler(t, "include=buf.protoschema.test.v1.Product+**.NestedTestAllTypes")
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			"buf.protoschema.test.v1.Product.pubsub.proto",
+			"bufext.cel.expr.conformance.proto3.NestedTestAllTypes.pubsub.proto",
+		}, getFileNames(response))
+	})
+	t.Run("include_regex", func(t *testing.T) {
+		t.Parallel()
+		response, err := runPubsubHandler(t, `include_regex=buf\.protoschema\.test\.v1\.Pro.*`)
+		require.NoError(t, err)
+		require.Equal(t, []string{"buf.protoschema.test.v1.Product.pubsub.proto"}, getFileNames(response))
+	})
+	t.Run("file_name", func(t *testing.T) {
+		t.Parallel()
+		response, err := runPubsubHandler(t, "include=**.Product,format=both,file_name={{.File}}/{{.Name}}")
+		require.NoError(t, err)
+		require.Equal(t, []string{
+			"buf/protoschema/test/v1/examples/Product.pubsub.avsc",
+			"buf/protoschema/test/v1/examples/Product.pubsub.proto",
+		}, getFileNames(response))
+	})
+	t.Run("file_name_conflict", func(t *testing.T) {
+		t.Parallel()
+		_, err := runPubsubHandler(t, "file_name={{.Package}}")
+		require.ErrorContains(t, err, "already used")
+	})
+	t.Run("skip_types", func(t *testing.T) {
+		t.Parallel()
+		response, err := runPubsubHandler(t, "include=**.TestAllTypes,skip_types=google.protobuf.Timestamp")
+		require.NoError(t, err)
+		require.Len(t, response.GetFile(), 1)
+		content := response.GetFile()[0].GetContent()
+		require.Contains(t, content, `import "google/protobuf/timestamp.proto";`)
+		require.Contains(t, content, "google.protobuf.Timestamp single_timestamp = 102;")
+		require.NotContains(t, content, "Inline_google_protobuf_Timestamp")
+	})
+	t.Run("preserve_options", func(t *testing.T) {
+		t.Parallel()
+		response, err := runPubsubHandler(t, "include=**.TestAllTypes,preserve_options=deprecated")
+		require.NoError(t, err)
+		require.Len(t, response.GetFile(), 1)
+		require.NotContains(t, response.GetFile()[0].GetContent(), "ctype")
+		response, err = runPubsubHandler(t, "include=**.TestAllTypes,preserve_options=ctype")
+		require.NoError(t, err)
+		require.Len(t, response.GetFile(), 1)
+		require.Contains(t, response.GetFile()[0].GetContent(), "ctype")
+	})
+	t.Run("name_mangling", func(t *testing.T) {
+		t.Parallel()
+		response, err := runPubsubHandler(t, "include=**.ConstraintTests,name_mangling=short")
+		require.NoError(t, err)
+		require.Len(t, response.GetFile(), 1)
+		content := response.GetFile()[0].GetContent()
+		require.Contains(t, content, "repeated Inline_ConstraintTest test_cases = 1;")
+		require.Contains(t, content, "message Inline_google_protobuf_Duration_")
+	})
+	t.Run("format_options", func(t *testing.T) {
+		t.Parallel()
+		// The Avro schema describes the protobuf schema generated with the same options.
+		response, err := runPubsubHandler(t, "include=**.ConstraintTests,format=both,syntax=proto3,name_mangling=short")
+		require.NoError(t, err)
+		require.Len(t, response.GetFile(), 2)
+		avroContent := response.GetFile()[1].GetContent()
+		protoContent := response.GetFile()[0].GetContent()
+		require.Contains(t, protoContent, "repeated Inline_ConstraintTest test_cases = 1;")
+		require.Contains(t, avroContent, `"name": "ConstraintTests.Inline_ConstraintTest"`)
+		// Implicit presence fields are not nullable in proto3.
+		require.Contains(t, protoContent, "string string_value = ")
+		require.Regexp(t, `"name": "string_value",\s+"type": "string"`, avroContent)
+	})
+	t.Run("preserve_extensions", func(t *testing.T) {
+		t.Parallel()
+		response, err := runPubsubHandler(t, "include=**.ConstraintTest,preserve_extensions=buf.validate.field")
+		require.NoError(t, err)
+		require.Len(t, response.GetFile(), 1)
+		content := response.GetFile()[0].GetContent()
+		require.Contains(t, content, `import "google/protobuf/descriptor.proto";`)
+		require.Contains(t, content, "extend google.protobuf.FieldOptions {")
+		require.Contains(t, content, "(Inline_buf_validate_field) = { string: { len: 5 } }")
+		require.NotContains(t, content, "Inline_buf_validate_message")
+	})
+}
+
+func testPubsubHandler(t *testing.T, parameter string, goldenPath string, extensions []string) {
+	t.Helper()
+
+	response, err := runPubsubHandler(t, parameter)
+	require.NoError(t, err)
+
+	wantFiles := getFileNames(response)
+	require.Equal(t, wantFiles, gatherGoldenFiles(t, goldenPath, extensions))
+
+	for _, file := range response.GetFile() {
+		filename := path.Join(goldenPath, file.GetName())
+		want, err := os.ReadFile(filename)
+		require.NoError(t, err)
+		require.Equal(t, string(want), file.GetContent())
+	}
+}
+
+func runPubsubHandler(t *testing.T, parameter string) (*pluginpb.CodeGeneratorResponse, error) {
+	t.Helper()
+
 	inputImage := filepath.FromSlash("../../../testdata/codegenrequest/input.json")
 
 	by, err := os.ReadFile(inputImage)
@@ -47,7 +200,7 @@ func TestPubsubHandler(t *testing.T) {
 	require.NoError(t, err)
 	image, err := bufimage.NewImageForProto(protoImage)
 	require.NoError(t, err)
-	codeGeneratorRequest, err := bufimage.ImageToCodeGeneratorRequest(image, "", nil, false, false)
+	codeGeneratorRequest, err := bufimage.ImageToCodeGeneratorRequest(image, parameter, nil, false, false)
 	require.NoError(t, err)
 
 	request, err := protoencoding.NewWireMarshaler().Marshal(codeGeneratorRequest)
@@ -64,37 +217,38 @@ func TestPubsubHandler(t *testing.T) {
 		},
 		protoplugin.HandlerFunc(Handle),
 	)
-	require.NoError(t, err)
+	if err != nil {
+		return nil, err
+	}
 	require.Empty(t, stderr.String())
 
 	response := new(pluginpb.CodeGeneratorResponse)
 	err = protoencoding.NewWireUnmarshaler(nil).Unmarshal(stdout.Bytes(), response)
 	require.NoError(t, err)
+	return response, nil
+}
 
-	wantFiles := make([]string, 0, len(response.GetFile()))
-	for _, file := range response.GetFile() {
-		wantFiles = append(wantFiles, file.GetName())
-	}
-	slices.Sort(wantFiles)
-	require.Equal(t, wantFiles, gatherGoldenFiles(t, goldenPath))
-
+// getFileNames returns the sorted names of the files in the given response.
+func getFileNames(response *pluginpb.CodeGeneratorResponse) []string {
+	fileNames := make([]string, 0, len(response.GetFile()))
 	for _, file := range response.GetFile() {
-		filename := path.Join(goldenPath, file.GetName())
-		want, err := os.ReadFile(filename)
-		require.NoError(t, err)
-		require.Equal(t, string(want), file.GetContent())
+		fileNames = append(fileNames, file.GetName())
 	}
+	slices.Sort(fileNames)
+	return fileNames
 }
 
-func gatherGoldenFiles(t *testing.T, dir string) []string {
+func gatherGoldenFiles(t *testing.T, dir string, extensions []string) []string {
 	t.Helper()
 
 	entries, err := os.ReadDir(dir)
 	require.NoError(t, err)
 	var files []string
 	for _, entry := range entries {
-		if strings.HasSuffix(entry.Name(), pubsub.FileExtension) {
-			files = append(files, entry.Name())
+		for _, extension := range extensions {
+			if strings.HasSuffix(entry.Name(), extension) {
+				files = append(files, entry.Name())
+			}
 		}
 	}
 	slices.Sort(files)
@@ -0,0 +1,210 @@
+// Copyright 2024-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pubsub
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Incompatibility is a breaking change between two revisions of a PubSub schema.
+type Incompatibility struct {
+	// Path is the full name of the changed element in the previous revision.
+	Path protoreflect.FullName
+	// Message describes the change.
+	Message string
+}
+
+// String returns the path and message of the incompatibility.
+func (i Incompatibility) String() string {
+	return fmt.Sprintf("%s: %s", i.Path, i.Message)
+}
+
+// CheckCompatibility returns the breaking changes from the previous to the current revision of a
+// PubSub schema, given the normalized root messages of both.
+//
+// Messages encoded with either revision must be readable with the other in the binary encoding.
+// The following changes are reported:
+//   - Fields removed without reserving their number, which a later revision could reuse.
+//   - Field numbers reused by a field with a different name.
+//   - Fields changing type, including the (mangled) name of a message or enum type.
+//   - Fields changing between singular and repeated, or from or to required.
+//   - Enum values that are removed or renumbered.
+//   - Nested messages and enums that are renamed or removed.
+//
+// Changes that only break the JSON encoding, e.g. removing a field with a reserved number, are
+// not reported.
+func CheckCompatibility(previous, current protoreflect.MessageDescriptor) []Incompatibility {
+	checker := &compatibilityChecker{
+		checked: make(map[protoreflect.FullName]struct{}),
+	}
+	if previous.Name() != current.Name() {
+		checker.add(previous, "message renamed to %q", current.Name())
+	}
+	checker.checkMessage(previous, current)
+	return checker.result
+}
+
+type compatibilityChecker struct {
+	// checked is the set of messages and enums in the previous revision that have been checked.
+	checked map[protoreflect.FullName]struct{}
+	result  []Incompatibility
+}
+
+func (c *compatibilityChecker) add(desc protoreflect.Descriptor, format string, args ...any) {
+	c.result = append(c.result, Incompatibility{
+		Path:    desc.FullName(),
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// check records the given type of the previous revision as checked, returning false if it was
+// already checked.
+func (c *compatibilityChecker) check(desc protoreflect.Descriptor) bool {
+	if _, ok := c.checked[desc.FullName()]; ok {
+		return false
+	}
+	c.checked[desc.FullName()] = struct{}{}
+	return true
+}
+
+func (c *compatibilityChecker) checkMessage(previous, current protoreflect.MessageDescriptor) {
+	if !c.check(previous) {
+		return
+	}
+	for i := range previous.Fields().Len() {
+		prevField := previous.Fields().Get(i)
+		curField := current.Fields().ByNumber(prevField.Number())
+		switch {
+		case curField != nil:
+			c.checkField(prevField, curField)
+		case !current.ReservedRanges().Has(prevField.Number()):
+			c.add(prevField, "field removed without reserving number %d", prevField.Number())
+		}
+	}
+	// Nested types are named after the types they inline, so a renamed type is not the same type.
+	for i := range previous.Messages().Len() {
+		prevMsg := previous.Messages().Get(i)
+		if prevMsg.IsMapEntry() {
+			continue // Checked with the map field.
+		}
+		curMsg := current.Messages().ByName(prevMsg.Name())
+		if curMsg == nil {
+			c.add(prevMsg, "message renamed or removed")
+			continue
+		}
+		c.checkMessage(prevMsg, curMsg)
+	}
+	for i := range previous.Enums().Len() {
+		prevEnum := previous.Enums().Get(i)
+		curEnum := current.Enums().ByName(prevEnum.Name())
+		if curEnum == nil {
+			c.add(prevEnum, "enum renamed or removed")
+			continue
+		}
+		c.checkEnum(prevEnum, curEnum)
+	}
+}
+
+func (c *compatibilityChecker) checkField(previous, current protoreflect.FieldDescriptor) {
+	if previous.Name() != current.Name() {
+		c.add(previous, "field number %d reused by field %q", previous.Number(), current.Name())
+		return
+	}
+	if prevType, curType := getTypeName(previous), getTypeName(current); prevType != curType {
+		c.add(previous, "type changed from %s to %s", prevType, curType)
+		return
+	}
+	if previous.IsList() != current.IsList() {
+		if previous.IsList() {
+			c.add(previous, "changed from repeated to singular")
+		} else {
+			c.add(previous, "changed from singular to repeated")
+		}
+	}
+	switch {
+	case previous.Cardinality() == protoreflect.Required && current.Cardinality() != protoreflect.Required:
+		c.add(previous, "changed from required to optional")
+	case previous.Cardinality() != protoreflect.Required && current.Cardinality() == protoreflect.Required:
+		c.add(previous, "changed from optional to required")
+	}
+	if previous.IsMap() {
+		previous, current = previous.MapValue(), current.MapValue()
+	}
+	switch {
+	case previous.Message() != nil:
+		c.checkMessage(previous.Message(), current.Message())
+	case previous.Enum() != nil:
+		c.checkEnum(previous.Enum(), current.Enum())
+	}
+}
+
+func (c *compatibilityChecker) checkEnum(previous, current protoreflect.EnumDescriptor) {
+	if !c.check(previous) {
+		return
+	}
+	for i := range previous.Values().Len() {
+		prevValue := previous.Values().Get(i)
+		curValue := current.Values().ByName(prevValue.Name())
+		switch {
+		case curValue == nil:
+			c.add(prevValue, "enum value removed")
+		case curValue.Number() != prevValue.Number():
+			c.add(prevValue, "enum value number changed from %d to %d", prevValue.Number(), curValue.Number())
+		}
+	}
+}
+
+// getTypeName returns the name of the type of a single value of the given field, relative to the
+// root message for inlined types.
+func getTypeName(field protoreflect.FieldDescriptor) string {
+	switch {
+	case field.IsMap():
+		return fmt.Sprintf("map<%s, %s>", getTypeName(field.MapKey()), getTypeName(field.MapValue()))
+	case field.Message() != nil:
+		return relativeName(field.ParentFile(), field.Message())
+	case field.Enum() != nil:
+		return relativeName(field.ParentFile(), field.Enum())
+	default:
+		return field.Kind().String()
+	}
+}
+
+// relativeName returns the name of the given type, relative to the root message if it is inlined
+// into the given schema file.
+//
+// Types that are not inlined, e.g. skipped types, keep their full name.
+func relativeName(file protoreflect.FileDescriptor, desc protoreflect.Descriptor) string {
+	if desc.ParentFile().Path() != file.Path() {
+		return string(desc.FullName())
+	}
+	var path []string
+	for {
+		parent, ok := desc.Parent().(protoreflect.MessageDescriptor)
+		if !ok {
+			break
+		}
+		path = append(path, string(desc.Name()))
+		desc = parent
+	}
+	if len(path) == 0 {
+		return string(desc.Name()) // The root message.
+	}
+	slices.Reverse(path)
+	return strings.Join(path, ".")
+}
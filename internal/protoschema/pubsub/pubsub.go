@@ -15,38 +15,68 @@
 package pubsub
 
 import (
+	"fmt"
+
+	"github.com/bufbuild/protoschema-plugins/internal/protoschema/avro"
 	"github.com/bufbuild/protoschema-plugins/internal/protoschema/normalize"
 	"github.com/jhump/protoreflect/desc" //nolint:staticcheck
 	"github.com/jhump/protoreflect/desc/protoprint"
 	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
 	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
 	"google.golang.org/protobuf/types/descriptorpb"
 )
 
 const (
 	// FileExtension is the file extension for the PubSub schema files.
 	FileExtension = "pubsub.proto"
+	// AvroFileExtension is the file extension for the PubSub Avro schema files.
+	AvroFileExtension = "pubsub.avsc"
 )
 
+// GeneratorOption is an option for Generate.
+type GeneratorOption func(*generator)
+
+// WithSyntax generates the schema as a file of the given syntax, instead of proto2.
+//
+// Unlike proto2, proto3 and editions preserve the field presence and enum openness of
+// proto3 messages. See normalize.Syntax for the semantics preserved by each syntax.
+func WithSyntax(syntax normalize.Syntax) GeneratorOption {
+	return func(g *generator) {
+		g.syntax = syntax
+	}
+}
+
+// WithNormalizerOptions normalizes the message with the given options, e.g. to skip types.
+func WithNormalizerOptions(opts ...normalize.NormalizerOption) GeneratorOption {
+	return func(g *generator) {
+		g.normalizerOpts = append(g.normalizerOpts, opts...)
+	}
+}
+
+type generator struct {
+	syntax         normalize.Syntax
+	normalizerOpts []normalize.NormalizerOption
+}
+
 // Generate generates a PubSub schema in the form of a single self-contained messaged normalized to
-// proto2 for the given message descriptor.
-func Generate(input protoreflect.MessageDescriptor) (string, error) {
-	normalizer := normalize.NewNormalizer()
-	rootMsg, err := normalizer.Normalize(input)
+// proto2 (or the syntax given by WithSyntax) for the given message descriptor.
+func Generate(input protoreflect.MessageDescriptor, opts ...GeneratorOption) (string, error) {
+	file, imports, err := normalizeFile(input, opts)
 	if err != nil {
 		return "", err
 	}
-	file := &descriptorpb.FileDescriptorProto{
-		Name: proto.String(FileExtension),
-		// TODO: If/when Pub/Bub schemas support editions, use Edition 2023 so
-		//       there is no loss of fidelity for syntax-specific semantics
-		//       (such as field presence).
-		Syntax: proto.String("proto2"),
-		MessageType: []*descriptorpb.DescriptorProto{
-			rootMsg,
-		},
+	// Import the files of any skipped types and extended options.
+	deps := make([]*desc.FileDescriptor, 0, len(imports))
+	for _, importDesc := range imports {
+		dep, err := desc.WrapFile(importDesc)
+		if err != nil {
+			return "", err
+		}
+		deps = append(deps, dep)
 	}
-	fileDesc, err := desc.CreateFileDescriptor(file)
+	fileDesc, err := desc.CreateFileDescriptor(file, deps...)
 	if err != nil {
 		return "", err
 	}
@@ -55,3 +85,62 @@ func Generate(input protoreflect.MessageDescriptor) (string, error) {
 	}
 	return printer.PrintProtoToString(fileDesc)
 }
+
+// GenerateAvro generates a PubSub schema in the form of a single self-contained Avro record for the
+// given message descriptor.
+//
+// The record is generated for the message returned by Generate with the same options, so the
+// records and enums are named like the inlined types of the protobuf schema, and have the same
+// fields. The Avro JSON encoding differs from the protobuf JSON encoding, e.g. for nullable fields
+// and 64-bit integers.
+func GenerateAvro(input protoreflect.MessageDescriptor, opts ...GeneratorOption) (string, error) {
+	file, imports, err := normalizeFile(input, opts)
+	if err != nil {
+		return "", err
+	}
+	files := &protoregistry.Files{}
+	for _, importDesc := range imports {
+		if err := files.RegisterFile(importDesc); err != nil {
+			return "", fmt.Errorf("failed to register %q: %w", importDesc.Path(), err)
+		}
+	}
+	fileDesc, err := protodesc.NewFile(file, files)
+	if err != nil {
+		return "", fmt.Errorf("failed to create normalized file of %q: %w", input.FullName(), err)
+	}
+	return avro.Generate(fileDesc.Messages().Get(0), avro.WithNormalized())
+}
+
+// normalizeFile returns the file of the normalized message for the given message descriptor, and
+// the files it imports.
+func normalizeFile(
+	input protoreflect.MessageDescriptor,
+	opts []GeneratorOption,
+) (*descriptorpb.FileDescriptorProto, []protoreflect.FileDescriptor, error) {
+	generator := &generator{
+		syntax: normalize.SyntaxProto2,
+	}
+	for _, opt := range opts {
+		opt(generator)
+	}
+	normalizer := normalize.NewNormalizer(append(generator.normalizerOpts, normalize.WithSyntax(generator.syntax))...)
+	rootMsg, err := normalizer.Normalize(input)
+	if err != nil {
+		return nil, nil, err
+	}
+	file := &descriptorpb.FileDescriptorProto{
+		Name:   proto.String(FileExtension),
+		Syntax: proto.String(string(generator.syntax)),
+		MessageType: []*descriptorpb.DescriptorProto{
+			rootMsg,
+		},
+	}
+	if generator.syntax == normalize.SyntaxEditions {
+		file.Edition = normalize.Edition.Enum()
+	}
+	imports := normalizer.Imports()
+	for _, importDesc := range imports {
+		file.Dependency = append(file.Dependency, importDesc.Path())
+	}
+	return file, imports, nil
+}
@@ -15,12 +15,23 @@
 package pubsub
 
 import (
+	"encoding/json"
 	"fmt"
 	"path/filepath"
 	"testing"
 
+	"buf.build/gen/go/bufbuild/protovalidate/protocolbuffers/go/buf/validate"
+	"github.com/bufbuild/protocompile"
 	"github.com/bufbuild/protoschema-plugins/internal/protoschema/golden"
+	"github.com/bufbuild/protoschema-plugins/internal/protoschema/normalize"
+	"github.com/linkedin/goavro/v2"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
 )
 
 func TestPubSubGolden(t *testing.T) {
@@ -34,5 +45,376 @@ func TestPubSubGolden(t *testing.T) {
 		require.NoError(t, err)
 		err = golden.CheckGolden(fmt.Sprintf("%s.%s", filePath, FileExtension), data)
 		require.NoError(t, err)
+		avroData, err := GenerateAvro(testDesc)
+		require.NoError(t, err)
+		err = golden.CheckGolden(fmt.Sprintf("%s.%s", filePath, AvroFileExtension), avroData)
+		require.NoError(t, err)
+		_, err = goavro.NewCodec(avroData)
+		require.NoError(t, err)
+	}
+}
+
+func TestPubSubSyntaxGolden(t *testing.T) {
+	t.Parallel()
+	testDescs, err := golden.GetTestDescriptors("../../testdata")
+	require.NoError(t, err)
+	for _, syntax := range []normalize.Syntax{normalize.SyntaxProto3, normalize.SyntaxEditions} {
+		dirPath := filepath.Join(filepath.FromSlash("../../testdata/pubsub"), string(syntax))
+		for _, testDesc := range testDescs {
+			filePath := filepath.Join(dirPath, fmt.Sprintf("%s.%s", testDesc.FullName(), FileExtension))
+			data, err := Generate(testDesc, WithSyntax(syntax))
+			require.NoError(t, err)
+			err = golden.CheckGolden(filePath, data)
+			require.NoError(t, err)
+
+			// The schema must compile, and preserve the semantics of the fields.
+			compiler := protocompile.Compiler{
+				Resolver: &protocompile.SourceResolver{
+					Accessor: protocompile.SourceAccessorFromMap(map[string]string{FileExtension: data}),
+				},
+			}
+			files, err := compiler.Compile(t.Context(), FileExtension)
+			require.NoError(t, err, filePath)
+			msgDesc := files[0].Messages().Get(0)
+			for i := range testDesc.Fields().Len() {
+				want := testDesc.Fields().Get(i)
+				got := msgDesc.Fields().ByName(want.Name())
+				require.NotNil(t, got, want.FullName())
+				require.Equal(t, want.HasPresence(), got.HasPresence(), want.FullName())
+				require.Equal(t, want.IsPacked(), got.IsPacked(), want.FullName())
+				if want.Enum() != nil && got.Enum() != nil {
+					require.Equal(t, want.Enum().IsClosed(), got.Enum().IsClosed(), want.FullName())
+				}
+			}
+		}
+	}
+}
+
+func TestPubSubPreservedExtensions(t *testing.T) {
+	t.Parallel()
+	testDescs, err := golden.GetTestDescriptors("../../testdata")
+	require.NoError(t, err)
+	var testDesc protoreflect.MessageDescriptor
+	for _, desc := range testDescs {
+		if desc.FullName() == "buf.protoschema.test.v1.ConstraintTest" {
+			testDesc = desc
+		}
+	}
+	require.NotNil(t, testDesc)
+	for _, syntax := range []normalize.Syntax{normalize.SyntaxProto2, normalize.SyntaxProto3, normalize.SyntaxEditions} {
+		data, err := Generate(
+			testDesc,
+			WithSyntax(syntax),
+			WithNormalizerOptions(normalize.WithPreservedExtensions("buf.validate.field", "buf.validate.message")),
+		)
+		require.NoError(t, err)
+
+		// The schema must compile, and preserve the rules of the fields and messages.
+		compiler := protocompile.Compiler{
+			Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{
+				Accessor: protocompile.SourceAccessorFromMap(map[string]string{FileExtension: data}),
+			}),
+		}
+		files, err := compiler.Compile(t.Context(), FileExtension)
+		require.NoError(t, err, syntax)
+		msgDesc := files[0].Messages().Get(0)
+		for i := range testDesc.Fields().Len() {
+			want := testDesc.Fields().Get(i)
+			got := msgDesc.Fields().ByName(want.Name())
+			require.NotNil(t, got, want.FullName())
+			wantRules := proto.GetExtension(want.Options(), validate.E_Field)
+			gotOptions := &descriptorpb.FieldOptions{}
+			requireReparse(t, got.Options(), gotOptions)
+			require.True(t, proto.Equal(wantRules.(proto.Message), proto.GetExtension(gotOptions, validate.E_Field).(proto.Message)), want.FullName())
+		}
+		for i := range testDesc.Messages().Len() {
+			want := testDesc.Messages().Get(i)
+			got := msgDesc.Messages().ByName(want.Name())
+			require.NotNil(t, got, want.FullName())
+			wantRules := proto.GetExtension(want.Options(), validate.E_Message)
+			gotOptions := &descriptorpb.MessageOptions{}
+			requireReparse(t, got.Options(), gotOptions)
+			require.True(t, proto.Equal(wantRules.(proto.Message), proto.GetExtension(gotOptions, validate.E_Message).(proto.Message)), want.FullName())
+		}
+	}
+}
+
+// requireReparse unmarshals the given options into the given generated options, resolving the
+// extensions of the generated types.
+func requireReparse(t *testing.T, options proto.Message, into proto.Message) {
+	t.Helper()
+	data, err := proto.Marshal(options)
+	require.NoError(t, err)
+	require.NoError(t, proto.Unmarshal(data, into))
+}
+
+func TestPubSubAvroConvertedSample(t *testing.T) {
+	t.Parallel()
+	testDescs, err := golden.GetTestDescriptors("../../testdata")
+	require.NoError(t, err)
+	var testDesc protoreflect.MessageDescriptor
+	for _, desc := range testDescs {
+		if desc.FullName() == "bufext.cel.expr.conformance.proto3.TestAllTypes" {
+			testDesc = desc
+		}
 	}
+	require.NotNil(t, testDesc)
+
+	// Build the normalized message, as used by the protobuf schema.
+	rootMsg, err := normalize.NewNormalizer().Normalize(testDesc)
+	require.NoError(t, err)
+	fileDesc, err := protodesc.NewFile(&descriptorpb.FileDescriptorProto{
+		Name:        proto.String(FileExtension),
+		Syntax:      proto.String("proto2"),
+		MessageType: []*descriptorpb.DescriptorProto{rootMsg},
+	}, nil)
+	require.NoError(t, err)
+	msgDesc := fileDesc.Messages().Get(0)
+
+	avroData, err := GenerateAvro(testDesc)
+	require.NoError(t, err)
+	codec, err := goavro.NewCodec(avroData)
+	require.NoError(t, err)
+
+	// The protobuf JSON encoding of a message with a presence field, a oneof member and an
+	// int64 set is not valid Avro JSON.
+	msg := dynamicpb.NewMessage(msgDesc)
+	setField := func(name protoreflect.Name, value protoreflect.Value) {
+		field := msgDesc.Fields().ByName(name)
+		require.NotNil(t, field, name)
+		msg.Set(field, value)
+	}
+	setField("single_string", protoreflect.ValueOfString("a"))
+	setField("single_int64", protoreflect.ValueOfInt64(-2))
+	setField("single_nested_enum", protoreflect.ValueOfInt32(1))
+	setField("optional_null_value", protoreflect.ValueOfInt32(0))
+	protoJSON, err := protojson.MarshalOptions{UseProtoNames: true}.Marshal(msg)
+	require.NoError(t, err)
+	_, _, err = codec.NativeFromTextual(protoJSON)
+	require.Error(t, err, string(protoJSON))
+
+	// The Avro JSON encoding wraps the values of nullable fields, which are all the fields with
+	// presence in proto2, in their type, and encodes 64-bit integers as numbers.
+	avroJSON := toAvroJSON(t, avroData, protoJSON)
+	native, remaining, err := codec.NativeFromTextual(avroJSON)
+	require.NoError(t, err, string(avroJSON))
+	require.Empty(t, remaining)
+	record, ok := native.(map[string]any)
+	require.True(t, ok)
+	require.Equal(t, map[string]any{"string": "a"}, record["single_string"])
+	require.Equal(t, map[string]any{"long": int64(-2)}, record["single_int64"])
+	require.Equal(t, map[string]any{"int": int32(1)}, record["single_nested_enum"])
+	require.Equal(t, map[string]any{"int": int32(0)}, record["optional_null_value"])
+	require.Nil(t, record["single_nested_message"])
+}
+
+// toAvroJSON converts the protobuf JSON encoding of the scalar fields of the root message to the
+// Avro JSON encoding of the given schema.
+func toAvroJSON(t *testing.T, avroSchema string, protoJSON []byte) []byte {
+	t.Helper()
+	var schema struct {
+		Fields []struct {
+			Name string `json:"name"`
+			Type any    `json:"type"`
+		} `json:"fields"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(avroSchema), &schema))
+	var values map[string]any
+	require.NoError(t, json.Unmarshal(protoJSON, &values))
+	result := make(map[string]any, len(values))
+	for _, field := range schema.Fields {
+		value, ok := values[field.Name]
+		if !ok {
+			continue
+		}
+		fieldType := field.Type
+		union, nullable := fieldType.([]any)
+		if nullable {
+			fieldType = union[1]
+		}
+		typeName, ok := fieldType.(string)
+		require.True(t, ok, "field %s is not a scalar", field.Name)
+		if typeName == "long" {
+			// The protobuf JSON encoding of 64-bit integers is a string.
+			number, ok := value.(string)
+			require.True(t, ok)
+			value = json.Number(number)
+		}
+		if nullable {
+			value = map[string]any{typeName: value}
+		}
+		result[field.Name] = value
+	}
+	require.Len(t, result, len(values))
+	data, err := json.Marshal(result)
+	require.NoError(t, err)
+	return data
+}
+
+func TestCheckCompatibility(t *testing.T) {
+	t.Parallel()
+	previous := `
+syntax = "proto2";
+import "google/protobuf/timestamp.proto";
+message Schema {
+  required string id = 1;
+  optional int32 count = 2;
+  optional Inline_foo_v1_Item item = 3;
+  repeated string tags = 4;
+  optional google.protobuf.Timestamp time = 5;
+  map<string, Inline_foo_v1_Item> items = 6;
+  message Inline_foo_v1_Item {
+    optional Inline_foo_v1_Item parent = 1;
+    optional Inline_foo_v1_Kind.Kind kind = 2;
+  }
+  message Inline_foo_v1_Kind {
+    enum Kind {
+      KIND_UNSPECIFIED = 0;
+      KIND_A = 1;
+      KIND_B = 2;
+    }
+  }
+}
+`
+	for _, testCase := range []struct {
+		name    string
+		current string
+		want    []string
+	}{
+		{
+			name:    "unchanged",
+			current: previous,
+		},
+		{
+			name: "compatible",
+			current: `
+syntax = "proto2";
+import "google/protobuf/timestamp.proto";
+message Schema {
+  reserved 2;
+  reserved "count";
+  required string id = 1;
+  optional Inline_foo_v1_Item item = 3;
+  repeated string tags = 4;
+  optional google.protobuf.Timestamp time = 5;
+  map<string, Inline_foo_v1_Item> items = 6;
+  optional string name = 7;
+  message Inline_foo_v1_Item {
+    optional Inline_foo_v1_Item parent = 1;
+    optional Inline_foo_v1_Kind.Kind kind = 2;
+  }
+  message Inline_foo_v1_Kind {
+    enum Kind {
+      KIND_UNSPECIFIED = 0;
+      KIND_A = 1;
+      KIND_B = 2;
+      KIND_C = 3;
+    }
+  }
+  message Inline_foo_v1_Other {}
+}
+`,
+		},
+		{
+			name: "incompatible",
+			current: `
+syntax = "proto2";
+message Schema {
+  optional string id = 1;
+  optional string name = 2;
+  optional Inline_foo_v1_Item_0 item = 3;
+  optional string tags = 4;
+  optional int64 time = 5;
+  map<string, Inline_foo_v1_Item_0> items = 6;
+  message Inline_foo_v1_Item_0 {
+    optional Inline_foo_v1_Item_0 parent = 1;
+  }
+  message Inline_foo_v1_Kind {
+    enum Kind {
+      KIND_UNSPECIFIED = 0;
+      KIND_B = 1;
+    }
+  }
+}
+`,
+			want: []string{
+				"Schema.id: changed from required to optional",
+				"Schema.count: field number 2 reused by field \"name\"",
+				"Schema.item: type changed from Inline_foo_v1_Item to Inline_foo_v1_Item_0",
+				"Schema.tags: changed from repeated to singular",
+				"Schema.time: type changed from google.protobuf.Timestamp to int64",
+				"Schema.items: type changed from map<string, Inline_foo_v1_Item> to map<string, Inline_foo_v1_Item_0>",
+				"Schema.Inline_foo_v1_Item: message renamed or removed",
+				"Schema.Inline_foo_v1_Kind.KIND_A: enum value removed",
+				"Schema.Inline_foo_v1_Kind.KIND_B: enum value number changed from 2 to 1",
+			},
+		},
+		{
+			name: "removed",
+			current: `
+syntax = "proto2";
+import "google/protobuf/timestamp.proto";
+message Schema {
+  required string id = 1;
+  optional Inline_foo_v1_Item item = 3;
+  repeated string tags = 4;
+  optional google.protobuf.Timestamp time = 5;
+  message Inline_foo_v1_Item {
+    optional Inline_foo_v1_Kind.Kind kind = 2;
+  }
+  message Inline_foo_v1_Kind {
+    enum Kind {
+      KIND_UNSPECIFIED = 0;
+      KIND_A = 1;
+      KIND_B = 2;
+    }
+  }
+}
+`,
+			want: []string{
+				"Schema.count: field removed without reserving number 2",
+				"Schema.Inline_foo_v1_Item.parent: field removed without reserving number 1",
+				"Schema.items: field removed without reserving number 6",
+			},
+		},
+		{
+			name: "renamed",
+			current: `
+syntax = "proto2";
+message Renamed {}
+`,
+			want: []string{
+				"Schema: message renamed to \"Renamed\"",
+				"Schema.id: field removed without reserving number 1",
+				"Schema.count: field removed without reserving number 2",
+				"Schema.item: field removed without reserving number 3",
+				"Schema.tags: field removed without reserving number 4",
+				"Schema.time: field removed without reserving number 5",
+				"Schema.items: field removed without reserving number 6",
+				"Schema.Inline_foo_v1_Item: message renamed or removed",
+				"Schema.Inline_foo_v1_Kind: message renamed or removed",
+			},
+		},
+	} {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			var got []string
+			for _, incompatibility := range CheckCompatibility(compileSchema(t, previous), compileSchema(t, testCase.current)) {
+				got = append(got, incompatibility.String())
+			}
+			require.Equal(t, testCase.want, got)
+		})
+	}
+}
+
+func compileSchema(t *testing.T, data string) protoreflect.MessageDescriptor {
+	t.Helper()
+	compiler := protocompile.Compiler{
+		Resolver: protocompile.WithStandardImports(&protocompile.SourceResolver{
+			Accessor: protocompile.SourceAccessorFromMap(map[string]string{FileExtension: data}),
+		}),
+	}
+	files, err := compiler.Compile(t.Context(), FileExtension)
+	require.NoError(t, err)
+	return files[0].Messages().Get(0)
 }